@@ -0,0 +1,167 @@
+package cloudbacktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// routes registers the API endpoints. New endpoints are added here.
+func (s *Server) routes() {
+	s.handle("/ops/definition/get", s.getDefinition)
+	s.handle("/ops/definition/update", s.updateDefinition)
+	s.handle("/ops/definition/delete", s.deleteDefinition)
+}
+
+// handle registers an endpoint accepting a JSON POST request.
+func (s *Server) handle(path string, handler func(w http.ResponseWriter, r *http.Request, body []byte)) {
+	s.mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed", fmt.Sprintf("%s is not allowed.", r.Method), nil)
+			return
+		}
+
+		body, err := readBody(r)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "InvalidRequest", err.Error(), nil)
+			return
+		}
+
+		handler(w, r, body)
+	})
+}
+
+func (s *Server) getDefinition(w http.ResponseWriter, r *http.Request, body []byte) {
+	var request Definition
+	if !decode(w, r, body, &request) || !validateKey(w, r, request) {
+		return
+	}
+
+	definition, ok := s.GetDefinition(request.Key())
+	if !ok {
+		writeError(w, r, http.StatusNotFound, "NotFound", "The backup definition does not exist.", nil)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, definition)
+}
+
+func (s *Server) updateDefinition(w http.ResponseWriter, r *http.Request, body []byte) {
+	var request Definition
+	if !decode(w, r, body, &request) || !validateKey(w, r, request) {
+		return
+	}
+
+	if request.Settings.Enabled {
+		fieldErrors := requireFields("Required when the backup is enabled.",
+			"settings.schedule", request.Settings.Schedule,
+			"settings.storage", request.Settings.Storage,
+			"settings.retention", request.Settings.Retention,
+		)
+
+		if len(fieldErrors) > 0 {
+			writeError(w, r, http.StatusBadRequest, "ValidationFailed", "The backup definition is invalid.", fieldErrors)
+			return
+		}
+	}
+
+	s.PutDefinition(request)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteDefinition(w http.ResponseWriter, r *http.Request, body []byte) {
+	var request Definition
+	if !decode(w, r, body, &request) || !validateKey(w, r, request) {
+		return
+	}
+
+	if _, ok := s.GetDefinition(request.Key()); !ok {
+		writeError(w, r, http.StatusNotFound, "NotFound", "The backup definition does not exist.", nil)
+		return
+	}
+
+	s.DeleteDefinition(request.Key())
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// validateKey rejects requests that do not identify a definition.
+func validateKey(w http.ResponseWriter, r *http.Request, definition Definition) bool {
+	fieldErrors := requireFields("Required.",
+		"platform", definition.Platform,
+		"account", definition.Account,
+		"subjectType", definition.SubjectType,
+		"subjectName", definition.SubjectName,
+	)
+
+	if len(fieldErrors) > 0 {
+		writeError(w, r, http.StatusBadRequest, "ValidationFailed", "The backup definition is invalid.", fieldErrors)
+		return false
+	}
+
+	return true
+}
+
+// requireFields returns a field error for every empty value. The arguments
+// are pairs of field name and value.
+func requireFields(message string, fieldsAndValues ...string) []fieldError {
+	var fieldErrors []fieldError
+
+	for i := 0; i+1 < len(fieldsAndValues); i += 2 {
+		if fieldsAndValues[i+1] == "" {
+			fieldErrors = append(fieldErrors, fieldError{Field: fieldsAndValues[i], Message: message})
+		}
+	}
+
+	return fieldErrors
+}
+
+type fieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type errorBody struct {
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	RequestID string       `json:"requestId,omitempty"`
+	Errors    []fieldError `json:"errors,omitempty"`
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, statusCode int, code, message string, fieldErrors []fieldError) {
+	writeJSON(w, statusCode, errorBody{
+		Code:      code,
+		Message:   message,
+		RequestID: r.Header.Get("X-Request-ID"),
+		Errors:    fieldErrors,
+	})
+}
+
+func decode(w http.ResponseWriter, r *http.Request, body []byte, value interface{}) bool {
+	if err := json.Unmarshal(body, value); err != nil {
+		writeError(w, r, http.StatusBadRequest, "InvalidRequest", fmt.Sprintf("The request body is not valid JSON: %s", err), nil)
+		return false
+	}
+
+	return true
+}
+
+// readBody reads the request body and restores it, so it can be read again
+// by the endpoint after being recorded.
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
@@ -0,0 +1,289 @@
+// Package cloudbacktest provides an in-memory fake of the Cloudback API for
+// offline provider and module tests.
+//
+// The fake keeps backup definitions in memory, checks the X-API-KEY header
+// and can be told to fail upcoming requests, e.g. to exercise retries:
+//
+//	server := cloudbacktest.NewServer(t, cloudbacktest.WithAPIKey("test"))
+//	server.InjectFault(cloudbacktest.Fault{Path: "/ops/definition/get", StatusCode: 503})
+//	t.Setenv("CLOUDBACK_ENDPOINT", server.URL)
+//	t.Setenv("CLOUDBACK_ALLOW_INSECURE_HTTP", "true")
+package cloudbacktest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Server is an in-memory fake of the Cloudback API. It implements
+// http.Handler, so it can be mounted in any HTTP server. Use NewServer to
+// get one listening on a local address.
+type Server struct {
+	// URL is the base URL of the server started by NewServer.
+	URL string
+
+	apiKey string
+	mux    *http.ServeMux
+
+	mu          sync.Mutex
+	definitions map[Key]Definition
+	faults      []Fault
+	requests    []Request
+}
+
+// Option customizes a Server.
+type Option func(*Server)
+
+// WithAPIKey makes the server reject requests that do not carry apiKey in
+// the X-API-KEY header. Without it any key is accepted.
+func WithAPIKey(apiKey string) Option {
+	return func(s *Server) {
+		s.apiKey = apiKey
+	}
+}
+
+// WithDefinitions seeds the server with existing backup definitions.
+func WithDefinitions(definitions ...Definition) Option {
+	return func(s *Server) {
+		for _, definition := range definitions {
+			s.definitions[definition.Key()] = definition
+		}
+	}
+}
+
+// Key identifies a backup definition.
+type Key struct {
+	Platform    string
+	Account     string
+	SubjectType string
+	SubjectName string
+}
+
+// Definition is a backup definition as stored by the fake.
+type Definition struct {
+	Platform    string   `json:"platform"`
+	Account     string   `json:"account"`
+	SubjectType string   `json:"subjectType"`
+	SubjectName string   `json:"subjectName"`
+	Settings    Settings `json:"settings"`
+}
+
+// Key returns the identity of the definition.
+func (d Definition) Key() Key {
+	return Key{
+		Platform:    d.Platform,
+		Account:     d.Account,
+		SubjectType: d.SubjectType,
+		SubjectName: d.SubjectName,
+	}
+}
+
+// Settings are the schedule settings of a backup definition.
+type Settings struct {
+	Enabled   bool   `json:"enabled"`
+	Schedule  string `json:"schedule"`
+	Storage   string `json:"storage"`
+	Retention string `json:"retention"`
+}
+
+// Fault makes the server answer upcoming requests with an error instead of
+// handling them.
+type Fault struct {
+	// Path restricts the fault to one endpoint. Empty matches every path.
+	Path string
+	// StatusCode is the status returned, e.g. 503. Zero closes the
+	// connection without a response, simulating a connection reset.
+	StatusCode int
+	// RetryAfter, when set, is sent as the Retry-After header.
+	RetryAfter time.Duration
+	// Body is sent as the response body. Defaults to an error document
+	// matching StatusCode.
+	Body string
+	// Delay is waited before answering.
+	Delay time.Duration
+	// Times is the number of requests the fault applies to. Zero means
+	// once.
+	Times int
+}
+
+// Request records a request received by the server.
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   []byte
+}
+
+// New returns a fake that is not listening anywhere.
+func New(opts ...Option) *Server {
+	s := &Server{
+		mux:         http.NewServeMux(),
+		definitions: map[Key]Definition{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.routes()
+
+	return s
+}
+
+// NewServer starts a fake listening on a local address. It is closed when
+// the test finishes.
+func NewServer(tb testing.TB, opts ...Option) *Server {
+	tb.Helper()
+
+	s := New(opts...)
+	server := httptest.NewServer(s)
+	tb.Cleanup(server.Close)
+
+	s.URL = server.URL
+
+	return s
+}
+
+// PutDefinition creates or replaces a backup definition, e.g. to simulate
+// a change made outside of Terraform.
+func (s *Server) PutDefinition(definition Definition) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.definitions[definition.Key()] = definition
+}
+
+// GetDefinition returns the stored backup definition.
+func (s *Server) GetDefinition(key Key) (Definition, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	definition, ok := s.definitions[key]
+
+	return definition, ok
+}
+
+// DeleteDefinition removes a backup definition, e.g. to simulate a
+// repository being deleted outside of Terraform.
+func (s *Server) DeleteDefinition(key Key) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.definitions, key)
+}
+
+// InjectFault queues a fault for upcoming requests. Faults are applied in
+// the order they were injected.
+func (s *Server) InjectFault(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fault.Times <= 0 {
+		fault.Times = 1
+	}
+
+	s.faults = append(s.faults, fault)
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := readBody(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "InvalidRequest", err.Error(), nil)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+	fault, faulted := s.takeFault(r.URL.Path)
+	s.mu.Unlock()
+
+	if faulted {
+		serveFault(w, r, fault)
+		return
+	}
+
+	if s.apiKey != "" && r.Header.Get("X-API-KEY") != s.apiKey {
+		writeError(w, r, http.StatusUnauthorized, "Unauthorized", "The API key is missing or invalid.", nil)
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// takeFault pops the next fault matching path. The caller holds s.mu.
+func (s *Server) takeFault(path string) (Fault, bool) {
+	for i, fault := range s.faults {
+		if fault.Path != "" && fault.Path != path {
+			continue
+		}
+
+		fault.Times--
+		if fault.Times == 0 {
+			s.faults = append(s.faults[:i], s.faults[i+1:]...)
+		} else {
+			s.faults[i] = fault
+		}
+
+		return fault, true
+	}
+
+	return Fault{}, false
+}
+
+func serveFault(w http.ResponseWriter, r *http.Request, fault Fault) {
+	if fault.Delay > 0 {
+		select {
+		case <-time.After(fault.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if fault.StatusCode == 0 {
+		if hijacker, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hijacker.Hijack(); err == nil {
+				_ = conn.Close()
+				return
+			}
+		}
+		fault.StatusCode = http.StatusBadGateway
+	}
+
+	if fault.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(fault.RetryAfter.Seconds())))
+	}
+
+	if fault.Body != "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(fault.StatusCode)
+		_, _ = w.Write([]byte(fault.Body))
+		return
+	}
+
+	writeError(w, r, fault.StatusCode, "InjectedFault", http.StatusText(fault.StatusCode), nil)
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(value)
+}
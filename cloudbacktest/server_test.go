@@ -0,0 +1,85 @@
+package cloudbacktest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func post(t *testing.T, server *Server, path, apiKey, body string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("unable to create request: %s", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-KEY", apiKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unable to send request: %s", err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	return resp
+}
+
+func TestServerStoresDefinitions(t *testing.T) {
+	server := NewServer(t, WithAPIKey("key"))
+
+	const definition = `{"platform":"GitHub","account":"testland","subjectType":"Repository","subjectName":"docs",` +
+		`"settings":{"enabled":true,"schedule":"Daily at 9 pm","storage":"Cloudback EU","retention":"Last 30 days"}}`
+
+	if resp := post(t, server, "/ops/definition/update", "key", definition); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	stored, ok := server.GetDefinition(Key{Platform: "GitHub", Account: "testland", SubjectType: "Repository", SubjectName: "docs"})
+	if !ok || stored.Settings.Schedule != "Daily at 9 pm" {
+		t.Fatalf("unexpected stored definition: %+v", stored)
+	}
+
+	const key = `{"platform":"GitHub","account":"testland","subjectType":"Repository","subjectName":"docs"}`
+
+	if resp := post(t, server, "/ops/definition/get", "key", key); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, got %d", resp.StatusCode)
+	}
+
+	if resp := post(t, server, "/ops/definition/get", "wrong", key); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", resp.StatusCode)
+	}
+
+	if resp := post(t, server, "/ops/definition/delete", "key", key); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, got %d", resp.StatusCode)
+	}
+
+	if resp := post(t, server, "/ops/definition/get", "key", key); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", resp.StatusCode)
+	}
+
+	if resp := post(t, server, "/ops/definition/delete", "key", key); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for a deleted definition, got %d", resp.StatusCode)
+	}
+}
+
+func TestServerInjectsFaults(t *testing.T) {
+	server := NewServer(t)
+	server.InjectFault(Fault{Path: "/ops/definition/get", StatusCode: http.StatusServiceUnavailable, Times: 2})
+
+	const key = `{"platform":"GitHub","account":"testland","subjectType":"Repository","subjectName":"docs"}`
+
+	for i := 0; i < 2; i++ {
+		if resp := post(t, server, "/ops/definition/get", "", key); resp.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("attempt %d: expected 503, got %d", i+1, resp.StatusCode)
+		}
+	}
+
+	if resp := post(t, server, "/ops/definition/get", "", key); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected the fault to be exhausted, got %d", resp.StatusCode)
+	}
+
+	if got := len(server.Requests()); got != 3 {
+		t.Errorf("expected 3 recorded requests, got %d", got)
+	}
+}
@@ -0,0 +1,48 @@
+// Command cloudback-fake-server serves the in-memory fake of the Cloudback
+// API from package cloudbacktest, so Terraform configurations and modules can
+// be tested without a Cloudback account:
+//
+//	cloudback-fake-server -addr 127.0.0.1:8080 &
+//	export CLOUDBACK_ENDPOINT=http://127.0.0.1:8080 CLOUDBACK_ALLOW_INSECURE_HTTP=true
+//	terraform apply
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+
+	"terraform-provider-cloudback/cloudbacktest"
+)
+
+func main() {
+	var addr, apiKey string
+
+	flag.StringVar(&addr, "addr", "127.0.0.1:8080", "address to listen on, use port 0 to pick a free one")
+	flag.StringVar(&apiKey, "api-key", os.Getenv("CLOUDBACK_API_KEY"), "API key required from clients, any key is accepted when empty")
+	flag.Parse()
+
+	var opts []cloudbacktest.Option
+	if apiKey != "" {
+		opts = append(opts, cloudbacktest.WithAPIKey(apiKey))
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	// Print the endpoint on stdout so that scripts can pick it up when a
+	// free port was requested.
+	fmt.Printf("CLOUDBACK_ENDPOINT=http://%s\n", listener.Addr())
+	fmt.Println("CLOUDBACK_ALLOW_INSECURE_HTTP=true")
+
+	err = http.Serve(listener, cloudbacktest.New(opts...))
+
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+}
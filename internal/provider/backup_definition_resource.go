@@ -2,18 +2,44 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &BackupDefinitionResource{}
 var _ resource.ResourceWithImportState = &BackupDefinitionResource{}
+var _ resource.ResourceWithModifyPlan = &BackupDefinitionResource{}
+var _ resource.ResourceWithUpgradeState = &BackupDefinitionResource{}
+var _ resource.ResourceWithConfigValidators = &BackupDefinitionResource{}
+
+// The on_destroy modes of a backup definition.
+const (
+	onDestroyDisable = "disable"
+	onDestroyDelete  = "delete"
+	onDestroyAbandon = "abandon"
+)
+
+// defaultBackupDefinitionTimeout bounds each operation on a backup
+// definition, including retries, unless overridden in the timeouts block.
+const defaultBackupDefinitionTimeout = 10 * time.Minute
 
 func NewBackupDefinitionResource() resource.Resource {
 	return &BackupDefinitionResource{}
@@ -21,17 +47,24 @@ func NewBackupDefinitionResource() resource.Resource {
 
 // BackupDefinitionResource defines the resource implementation.
 type BackupDefinitionResource struct {
-	client *CloudbackClient
+	client          *CloudbackClient
+	defaultPlatform types.String
+	defaultAccount  types.String
+	defaultSettings BackupDefinitionSettingsModel
+	readOnly        bool
 }
 
 // BackupDefinitionResourceModel describes the resource data model.
 type BackupDefinitionResourceModel struct {
+	ID          types.String                  `tfsdk:"id"`
 	Platform    types.String                  `tfsdk:"platform"`
 	Account     types.String                  `tfsdk:"account"`
 	SubjectType types.String                  `tfsdk:"subject_type"`
 	SubjectName types.String                  `tfsdk:"subject_name"`
 	Repository  types.String                  `tfsdk:"repository"`
 	Settings    BackupDefinitionSettingsModel `tfsdk:"settings"`
+	OnDestroy   types.String                  `tfsdk:"on_destroy"`
+	Timeouts    timeouts.Value                `tfsdk:"timeouts"`
 }
 
 type BackupDefinitionSettingsModel struct {
@@ -41,6 +74,31 @@ type BackupDefinitionSettingsModel struct {
 	Retention types.String `tfsdk:"retention"`
 }
 
+// backupDefinitionPlanModel is the BackupDefinitionResourceModel read while
+// planning, when settings may still be unknown, e.g. when built from the
+// outputs of other resources.
+type backupDefinitionPlanModel struct {
+	ID          types.String   `tfsdk:"id"`
+	Platform    types.String   `tfsdk:"platform"`
+	Account     types.String   `tfsdk:"account"`
+	SubjectType types.String   `tfsdk:"subject_type"`
+	SubjectName types.String   `tfsdk:"subject_name"`
+	Repository  types.String   `tfsdk:"repository"`
+	Settings    types.Object   `tfsdk:"settings"`
+	OnDestroy   types.String   `tfsdk:"on_destroy"`
+	Timeouts    timeouts.Value `tfsdk:"timeouts"`
+}
+
+// identity returns the attributes of m that identify the definition.
+func (m backupDefinitionPlanModel) identity() BackupDefinitionResourceModel {
+	return BackupDefinitionResourceModel{
+		Platform:    m.Platform,
+		Account:     m.Account,
+		SubjectType: m.SubjectType,
+		SubjectName: m.SubjectName,
+	}
+}
+
 func (r *BackupDefinitionResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_backup_definition"
 }
@@ -48,50 +106,91 @@ func (r *BackupDefinitionResource) Metadata(ctx context.Context, req resource.Me
 func (r *BackupDefinitionResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		MarkdownDescription: "Cloudback backup definition resource",
+		Version:             backupDefinitionSchemaVersion,
 
 		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Identifier of the definition in the form `platform/account/subject_type/subject_name`, also accepted by `terraform import`",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
 			"platform": schema.StringAttribute{
-				MarkdownDescription: "Platform name (e.g., GitHub, GitLab, AzureDevOps)",
-				Required:            true,
+				MarkdownDescription: "Platform name (e.g., GitHub, GitLab, AzureDevOps). Defaults to the provider `default_platform`. " + replaceDescription,
+				Optional:            true,
+				Computed:            true,
 			},
 			"account": schema.StringAttribute{
-				MarkdownDescription: "Account name",
-				Required:            true,
+				MarkdownDescription: "Account name. Defaults to the provider `default_account`. " + replaceDescription,
+				Optional:            true,
+				Computed:            true,
 			},
 			"subject_type": schema.StringAttribute{
-				MarkdownDescription: "Subject type (e.g., Repository, Project)",
+				MarkdownDescription: "Subject type (e.g., Repository, Project). Set to `Repository` when `repository` is used. " + replaceDescription,
 				Optional:            true,
+				Computed:            true,
 			},
 			"subject_name": schema.StringAttribute{
-				MarkdownDescription: "Subject name (repository name, project name, etc.)",
+				MarkdownDescription: "Subject name (repository name, project name, etc.). Set to `repository` when it is used. " + replaceDescription,
 				Optional:            true,
+				Computed:            true,
 			},
 			"repository": schema.StringAttribute{
-				MarkdownDescription: "Repository name (deprecated: use subject_type and subject_name instead)",
+				MarkdownDescription: "Repository name (deprecated: use subject_type and subject_name instead). " + replaceDescription,
+				DeprecationMessage:  "Use subject_type = \"Repository\" and subject_name instead. Existing state has been migrated, so switching the configuration does not replace the definition.",
 				Optional:            true,
 			},
+			"on_destroy": schema.StringAttribute{
+				MarkdownDescription: "What happens to the definition in Cloudback when the resource is destroyed: `disable` pauses the scheduled backup and keeps its settings, `delete` removes the definition, `abandon` leaves it untouched. Default is `disable`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(onDestroyDisable),
+				Validators: []validator.String{
+					stringvalidator.OneOf(onDestroyDisable, onDestroyDelete, onDestroyAbandon),
+				},
+			},
 			"settings": schema.SingleNestedAttribute{
 				Required: true,
 				Attributes: map[string]schema.Attribute{
 					"enabled": schema.BoolAttribute{
-						MarkdownDescription: "Whether the backup is scheduled",
-						Required:            true,
+						MarkdownDescription: "Whether the backup is scheduled. Defaults to the provider `default_settings`",
+						Optional:            true,
+						Computed:            true,
 					},
 					"schedule": schema.StringAttribute{
-						MarkdownDescription: "Backup schedule name",
-						Required:            true,
+						MarkdownDescription: "Backup schedule name. Defaults to the provider `default_settings`",
+						Optional:            true,
+						Computed:            true,
 					},
 					"storage": schema.StringAttribute{
-						MarkdownDescription: "Storage name",
-						Required:            true,
+						MarkdownDescription: "Storage name. Defaults to the provider `default_settings`",
+						Optional:            true,
+						Computed:            true,
 					},
 					"retention": schema.StringAttribute{
-						MarkdownDescription: "Retention policy name",
-						Required:            true,
+						MarkdownDescription: "Retention policy name. Defaults to the provider `default_settings`",
+						Optional:            true,
+						Computed:            true,
 					},
 				},
 			},
 		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+func (r *BackupDefinitionResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		subjectValidator{},
 	}
 }
 
@@ -101,18 +200,166 @@ func (r *BackupDefinitionResource) Configure(ctx context.Context, req resource.C
 		return
 	}
 
-	client, ok := req.ProviderData.(*CloudbackClient)
+	providerData, ok := req.ProviderData.(*CloudbackProviderData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *CloudbackClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *CloudbackProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = providerData.Client
+	r.defaultPlatform = providerData.DefaultPlatform
+	r.defaultAccount = providerData.DefaultAccount
+	r.defaultSettings = providerData.DefaultSettings
+	r.readOnly = providerData.ReadOnly
+}
+
+// requireClient reports an error when the API cannot be called because the
+// provider configuration is not known yet.
+func (r *BackupDefinitionResource) requireClient(diags *diag.Diagnostics) bool {
+	if r.client != nil {
+		return true
+	}
+
+	diags.AddError(
+		"Unknown Provider Configuration",
+		"The Cloudback API cannot be called because the provider configuration depends on values "+
+			"that are not known yet. Apply the resources the provider configuration depends on first.",
+	)
+
+	return false
+}
+
+// ModifyPlan fills the platform, account and settings left unset in the
+// configuration from the provider defaults, and the subject from the
+// repository attribute, so that plans show the effective values and a
+// changed default updates the definitions in place. In read-only mode it
+// rejects every plan that would change a definition.
+func (r *BackupDefinitionResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to fill in when the resource is destroyed.
+	if req.Plan.Raw.IsNull() {
+		r.checkReadOnly(&resp.Diagnostics, "destroy")
+		return
+	}
+
+	var config, plan backupDefinitionPlanModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.Platform = defaultValue(&resp.Diagnostics, path.Root("platform"), config.Platform, r.defaultPlatform, "the default_platform provider attribute")
+	plan.Account = defaultValue(&resp.Diagnostics, path.Root("account"), config.Account, r.defaultAccount, "the default_account provider attribute")
+
+	// The deprecated repository attribute names a subject of type
+	// Repository, so the rest of the resource only deals with subjects.
+	plan.SubjectType, plan.SubjectName = config.SubjectType, config.SubjectName
+	if !config.Repository.IsNull() && config.SubjectType.IsNull() && config.SubjectName.IsNull() {
+		plan.SubjectType = types.StringValue(repositorySubjectType)
+		plan.SubjectName = config.Repository
+	}
+
+	// Unknown settings stay unknown, their defaults are filled in once
+	// they are known.
+	if !config.Settings.IsUnknown() {
+		plan.Settings = r.planSettings(ctx, &resp.Diagnostics, config.Settings, plan.Settings)
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The id follows the identity, which may have been filled in from the
+	// provider defaults above or changed by a replacement.
+	plan.ID = plan.identity().canonicalID()
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+
+	if req.State.Raw.IsNull() {
+		r.checkReadOnly(&resp.Diagnostics, "create")
+		return
+	}
+
+	var state BackupDefinitionResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if changed := identityChanges(state, plan.identity()); len(changed) > 0 {
+		resp.RequiresReplace = append(resp.RequiresReplace, changed...)
+		resp.Diagnostics.AddWarning(replacementWarning(state, plan.identity(), changed))
+		r.checkReadOnly(&resp.Diagnostics, "replace")
+	} else if !resp.Plan.Raw.Equal(req.State.Raw) {
+		r.checkReadOnly(&resp.Diagnostics, "update")
+	}
+}
+
+// planSettings fills the settings left unset in the configuration from the
+// default_settings block of the provider.
+func (r *BackupDefinitionResource) planSettings(ctx context.Context, diags *diag.Diagnostics, configSettings, planSettings types.Object) types.Object {
+	var config, plan BackupDefinitionSettingsModel
+
+	diags.Append(configSettings.As(ctx, &config, basetypes.ObjectAsOptions{})...)
+	diags.Append(planSettings.As(ctx, &plan, basetypes.ObjectAsOptions{})...)
+
+	if diags.HasError() {
+		return planSettings
+	}
+
+	const fromDefaultSettings = "the default_settings block of the provider"
+	settingsPath := path.Root("settings")
+	plan.Enabled = defaultValue(diags, settingsPath.AtName("enabled"), config.Enabled, r.defaultSettings.Enabled, fromDefaultSettings)
+	plan.Schedule = defaultValue(diags, settingsPath.AtName("schedule"), config.Schedule, r.defaultSettings.Schedule, fromDefaultSettings)
+	plan.Storage = defaultValue(diags, settingsPath.AtName("storage"), config.Storage, r.defaultSettings.Storage, fromDefaultSettings)
+	plan.Retention = defaultValue(diags, settingsPath.AtName("retention"), config.Retention, r.defaultSettings.Retention, fromDefaultSettings)
+
+	settings, d := types.ObjectValueFrom(ctx, planSettings.AttributeTypes(ctx), plan)
+	diags.Append(d...)
+
+	return settings
+}
+
+// checkReadOnly rejects a plan to create, update or destroy a definition
+// when the provider is in read-only mode.
+func (r *BackupDefinitionResource) checkReadOnly(diags *diag.Diagnostics, action string) {
+	if !r.readOnly {
+		return
+	}
+
+	diags.AddError(
+		"Read-Only Mode",
+		fmt.Sprintf("The provider is in read-only mode, so this plan must not %s the backup definition. "+
+			"Remove read_only from the provider configuration and CLOUDBACK_READ_ONLY from the environment to make changes.", action),
+	)
+}
+
+// defaultValue returns the configured value, or the provider default when it
+// is unset. An attribute with neither is reported at attributePath, naming
+// fallbackSource as the place to set a default.
+func defaultValue[T interface{ IsNull() bool }](diags *diag.Diagnostics, attributePath path.Path, configured, fallback T, fallbackSource string) T {
+	if !configured.IsNull() {
+		return configured
+	}
+
+	if fallback.IsNull() {
+		diags.AddAttributeError(
+			attributePath,
+			"Missing Attribute Value",
+			fmt.Sprintf("The %s attribute must be set in the resource or through %s.", attributePath, fallbackSource),
+		)
+	}
+
+	return fallback
 }
 
 func (r *BackupDefinitionResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -121,33 +368,26 @@ func (r *BackupDefinitionResource) Create(ctx context.Context, req resource.Crea
 	// Read Terraform plan data into the model
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
 
-	if resp.Diagnostics.HasError() {
+	if !r.requireClient(&resp.Diagnostics) || resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Determine subject_type and subject_name for API call (backward compatibility)
-	var subjectType, subjectName string
-	if !data.SubjectType.IsNull() && !data.SubjectName.IsNull() {
-		// New format: use subject fields directly
-		subjectType = data.SubjectType.ValueString()
-		subjectName = data.SubjectName.ValueString()
-	} else if !data.Repository.IsNull() {
-		// Old format: derive from repository
-		subjectType = "Repository"
-		subjectName = data.Repository.ValueString()
-	} else {
-		resp.Diagnostics.AddError(
-			"Missing Required Fields",
-			"Either 'repository' or both 'subject_type' and 'subject_name' must be provided.",
-		)
+	timeout, diags := data.Timeouts.Create(ctx, defaultBackupDefinitionTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	err := r.client.UpdateBackupDefinition(
+		ctx,
 		data.Platform.ValueString(),
 		data.Account.ValueString(),
-		subjectType,
-		subjectName,
+		data.SubjectType.ValueString(),
+		data.SubjectName.ValueString(),
 		BackupDefinitionSettings{
 			Enabled:   data.Settings.Enabled.ValueBool(),
 			Schedule:  data.Settings.Schedule.ValueString(),
@@ -157,7 +397,7 @@ func (r *BackupDefinitionResource) Create(ctx context.Context, req resource.Crea
 	)
 
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update backup definition, got error: %s", err))
+		addClientError(ctx, &resp.Diagnostics, data, "Unable to update backup definition", err)
 		return
 	}
 
@@ -177,19 +417,40 @@ func (r *BackupDefinitionResource) Read(ctx context.Context, req resource.ReadRe
 		return
 	}
 
-	// Determine subject_type and subject_name for API call (backward compatibility)
-	var subjectType, subjectName string
-	if !data.SubjectType.IsNull() && !data.SubjectName.IsNull() {
-		subjectType = data.SubjectType.ValueString()
-		subjectName = data.SubjectName.ValueString()
-	} else if !data.Repository.IsNull() {
-		subjectType = "Repository"
-		subjectName = data.Repository.ValueString()
+	if r.client == nil {
+		// Keep the prior state until the provider configuration is known.
+		tflog.Warn(ctx, "Provider configuration is not known yet, skipping refresh of backup definition")
+		return
+	}
+
+	timeout, diags := data.Timeouts.Read(ctx, defaultBackupDefinitionTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	subjectType, subjectName := data.SubjectType.ValueString(), data.SubjectName.ValueString()
+
+	backupDefinition, err := r.client.GetBackupDefinition(ctx, data.Platform.ValueString(), data.Account.ValueString(), subjectType, subjectName)
+	if errors.Is(err, ErrNotFound) {
+		// The definition or its subject was removed outside of Terraform,
+		// drop it from state so that the next plan re-creates it.
+		tflog.Warn(ctx, "backup definition not found, removing from state", map[string]interface{}{
+			"platform":     data.Platform.ValueString(),
+			"account":      data.Account.ValueString(),
+			"subject_type": subjectType,
+			"subject_name": subjectName,
+		})
+		resp.State.RemoveResource(ctx)
+		return
 	}
 
-	backupDefinition, err := r.client.GetBackupDefinition(data.Platform.ValueString(), data.Account.ValueString(), subjectType, subjectName)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read backup definition, got error: %s", err))
+		addClientError(ctx, &resp.Diagnostics, data, "Unable to read backup definition", err)
 		return
 	}
 
@@ -200,6 +461,12 @@ func (r *BackupDefinitionResource) Read(ctx context.Context, req resource.ReadRe
 		Retention: types.StringValue(backupDefinition.Settings.Retention),
 	}
 
+	// State written before on_destroy existed, or by an import, gets the
+	// default mode instead of planning an update to set it.
+	if data.OnDestroy.IsNull() {
+		data.OnDestroy = types.StringValue(onDestroyDisable)
+	}
+
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -210,33 +477,26 @@ func (r *BackupDefinitionResource) Update(ctx context.Context, req resource.Upda
 	// Read Terraform plan data into the model
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
 
-	if resp.Diagnostics.HasError() {
+	if !r.requireClient(&resp.Diagnostics) || resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Determine subject_type and subject_name for API call (backward compatibility)
-	var subjectType, subjectName string
-	if !data.SubjectType.IsNull() && !data.SubjectName.IsNull() {
-		// New format: use subject fields directly
-		subjectType = data.SubjectType.ValueString()
-		subjectName = data.SubjectName.ValueString()
-	} else if !data.Repository.IsNull() {
-		// Old format: derive from repository
-		subjectType = "Repository"
-		subjectName = data.Repository.ValueString()
-	} else {
-		resp.Diagnostics.AddError(
-			"Missing Required Fields",
-			"Either 'repository' or both 'subject_type' and 'subject_name' must be provided.",
-		)
+	timeout, diags := data.Timeouts.Update(ctx, defaultBackupDefinitionTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	err := r.client.UpdateBackupDefinition(
+		ctx,
 		data.Platform.ValueString(),
 		data.Account.ValueString(),
-		subjectType,
-		subjectName,
+		data.SubjectType.ValueString(),
+		data.SubjectName.ValueString(),
 		BackupDefinitionSettings{
 			Enabled:   data.Settings.Enabled.ValueBool(),
 			Schedule:  data.Settings.Schedule.ValueString(),
@@ -246,7 +506,7 @@ func (r *BackupDefinitionResource) Update(ctx context.Context, req resource.Upda
 	)
 
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update backup definition, got error: %s", err))
+		addClientError(ctx, &resp.Diagnostics, data, "Unable to update backup definition", err)
 		return
 	}
 
@@ -262,46 +522,87 @@ func (r *BackupDefinitionResource) Delete(ctx context.Context, req resource.Dele
 	// Read Terraform prior state data into the model
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
 
+	if !r.requireClient(&resp.Diagnostics) || resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := data.Timeouts.Delete(ctx, defaultBackupDefinitionTimeout)
+	resp.Diagnostics.Append(diags...)
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Determine subject_type and subject_name for API call (backward compatibility)
-	var subjectType, subjectName string
-	if !data.SubjectType.IsNull() && !data.SubjectName.IsNull() {
-		subjectType = data.SubjectType.ValueString()
-		subjectName = data.SubjectName.ValueString()
-	} else if !data.Repository.IsNull() {
-		subjectType = "Repository"
-		subjectName = data.Repository.ValueString()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	subjectType, subjectName := data.SubjectType.ValueString(), data.SubjectName.ValueString()
+
+	var err error
+
+	switch data.OnDestroy.ValueString() {
+	case onDestroyAbandon:
+		tflog.Info(ctx, "leaving backup definition untouched, on_destroy is abandon", map[string]interface{}{
+			"platform":     data.Platform.ValueString(),
+			"account":      data.Account.ValueString(),
+			"subject_type": subjectType,
+			"subject_name": subjectName,
+		})
+		return
+	case onDestroyDelete:
+		err = r.client.DeleteBackupDefinition(ctx, data.Platform.ValueString(), data.Account.ValueString(), subjectType, subjectName)
+	default:
+		// Pause the backup but keep its settings, so it can be resumed.
+		err = r.client.UpdateBackupDefinition(
+			ctx,
+			data.Platform.ValueString(),
+			data.Account.ValueString(),
+			subjectType,
+			subjectName,
+			BackupDefinitionSettings{
+				Enabled:   false,
+				Schedule:  data.Settings.Schedule.ValueString(),
+				Storage:   data.Settings.Storage.ValueString(),
+				Retention: data.Settings.Retention.ValueString(),
+			},
+		)
 	}
 
-	err := r.client.UpdateBackupDefinition(
-		data.Platform.ValueString(),
-		data.Account.ValueString(),
-		subjectType,
-		subjectName,
-		BackupDefinitionSettings{
-			Enabled: false,
-		},
-	)
+	if errors.Is(err, ErrNotFound) {
+		// Nothing left to disable or delete.
+		return
+	}
 
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update backup definition, got error: %s", err))
+		addClientError(ctx, &resp.Diagnostics, data, "Unable to destroy backup definition", err)
 		return
 	}
 
-	r.LogUpdatedBackupDefinition(ctx, data)
+	tflog.Trace(ctx, "destroyed backup definition", map[string]interface{}{
+		"platform":     data.Platform.ValueString(),
+		"account":      data.Account.ValueString(),
+		"subject_type": subjectType,
+		"subject_name": subjectName,
+		"on_destroy":   data.OnDestroy.ValueString(),
+	})
 
-	// Save data into Terraform state
-	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	// The framework removes the resource from state once Delete returns
+	// without errors.
 }
 
 func (r *BackupDefinitionResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if !r.requireClient(&resp.Diagnostics) {
+		return
+	}
+
 	idParts := strings.Split(req.ID, "/")
 
 	var data BackupDefinitionResourceModel
 
+	// Start from the null timeouts block of the empty import state, so it
+	// carries the attribute types expected by the schema.
+	resp.Diagnostics.Append(resp.State.GetAttribute(ctx, path.Root("timeouts"), &data.Timeouts)...)
+
 	// Support both old format (platform/account/repository) and new format (platform/account/subject_type/subject_name)
 	if len(idParts) == 3 {
 		// Old format: platform/account/repository - assume Repository subject type
@@ -314,6 +615,8 @@ func (r *BackupDefinitionResource) ImportState(ctx context.Context, req resource
 		}
 		data.Platform = types.StringValue(idParts[0])
 		data.Account = types.StringValue(idParts[1])
+		data.SubjectType = types.StringValue(repositorySubjectType)
+		data.SubjectName = types.StringValue(idParts[2])
 		data.Repository = types.StringValue(idParts[2])
 	} else if len(idParts) == 4 {
 		// New format: platform/account/subject_type/subject_name
@@ -336,24 +639,23 @@ func (r *BackupDefinitionResource) ImportState(ctx context.Context, req resource
 		return
 	}
 
-	// Determine subject_type and subject_name for API call
-	var subjectType, subjectName string
-	if !data.SubjectType.IsNull() && !data.SubjectName.IsNull() {
-		subjectType = data.SubjectType.ValueString()
-		subjectName = data.SubjectName.ValueString()
-	} else if !data.Repository.IsNull() {
-		subjectType = "Repository"
-		subjectName = data.Repository.ValueString()
-	}
-
 	backupDefinition, err := r.client.GetBackupDefinition(
+		ctx,
 		data.Platform.ValueString(),
 		data.Account.ValueString(),
-		subjectType,
-		subjectName)
+		data.SubjectType.ValueString(),
+		data.SubjectName.ValueString())
+
+	if errors.Is(err, ErrNotFound) {
+		resp.Diagnostics.AddError(
+			"Cannot Import Non-Existent Remote Object",
+			fmt.Sprintf("No backup definition was found for import identifier %q.", req.ID),
+		)
+		return
+	}
 
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read backup definition, got error: %s", err))
+		addClientError(ctx, &resp.Diagnostics, data, "Unable to read backup definition", err)
 		return
 	}
 
@@ -363,11 +665,80 @@ func (r *BackupDefinitionResource) ImportState(ctx context.Context, req resource
 		Storage:   types.StringValue(backupDefinition.Settings.Storage),
 		Retention: types.StringValue(backupDefinition.Settings.Retention),
 	}
+	data.ID = data.canonicalID()
 
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
+// backupDefinitionAttributePaths maps the field names reported in API
+// validation errors to the matching attributes of the resource schema.
+var backupDefinitionAttributePaths = map[string]path.Path{
+	"platform":           path.Root("platform"),
+	"account":            path.Root("account"),
+	"subject_type":       path.Root("subject_type"),
+	"subject_name":       path.Root("subject_name"),
+	"settings":           path.Root("settings"),
+	"settings.enabled":   path.Root("settings").AtName("enabled"),
+	"settings.schedule":  path.Root("settings").AtName("schedule"),
+	"settings.storage":   path.Root("settings").AtName("storage"),
+	"settings.retention": path.Root("settings").AtName("retention"),
+}
+
+// addClientError reports a client error. Field validation errors returned
+// by the API are attached to the matching attribute so Terraform can point
+// at the offending line of the configuration. The operation context ctx
+// tells a missed operation deadline apart from a single request timing out,
+// since both surface as context.DeadlineExceeded.
+func addClientError(ctx context.Context, diags *diag.Diagnostics, data BackupDefinitionResourceModel, summary string, err error) {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		diags.AddError(
+			"Operation Timed Out",
+			fmt.Sprintf("%s before the deadline of the operation, got error: %s. "+
+				"The deadline can be raised in the timeouts block of the resource.", summary, err),
+		)
+		return
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+		diags.AddError(
+			"Request Timed Out",
+			fmt.Sprintf("%s, the Cloudback API did not answer in time, got error: %s. "+
+				"The time allowed per request can be raised with the request_timeout provider attribute.", summary, err),
+		)
+		return
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || len(apiErr.FieldErrors) == 0 {
+		diags.AddError("Client Error", fmt.Sprintf("%s, got error: %s", summary, err))
+		return
+	}
+
+	for _, fieldErr := range apiErr.FieldErrors {
+		detail := fmt.Sprintf("%s, got error: %s", summary, fieldErr.Message)
+		if apiErr.RequestID != "" {
+			detail += fmt.Sprintf(" (request ID: %s)", apiErr.RequestID)
+		}
+
+		attributePath, ok := backupDefinitionAttributePaths[fieldErr.Field]
+
+		if !ok {
+			diags.AddError("Client Error", fmt.Sprintf("%s: %s", fieldErr.Field, detail))
+			continue
+		}
+
+		// Definitions using the deprecated repository attribute derive the
+		// subject name from it.
+		if fieldErr.Field == "subject_name" && !data.Repository.IsNull() {
+			attributePath = path.Root("repository")
+		}
+
+		diags.AddAttributeError(attributePath, "Invalid Backup Definition", detail)
+	}
+}
+
 func (r *BackupDefinitionResource) LogUpdatedBackupDefinition(ctx context.Context, data BackupDefinitionResourceModel) {
 	logData := map[string]interface{}{
 		"platform":  data.Platform.ValueString(),
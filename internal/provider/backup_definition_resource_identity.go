@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// replaceDescription explains in the documentation why the identity
+// attributes force a new definition.
+const replaceDescription = "Changing it replaces the definition, handling the previous one according to `on_destroy`."
+
+// repositorySubjectType is the subject_type of the subject named by the
+// deprecated repository attribute.
+const repositorySubjectType = "Repository"
+
+// identityChanges returns the identity attributes whose change moves the
+// definition to another subject. It compares the values resolved from the
+// provider defaults and the repository attribute, which attribute plan
+// modifiers only see as unknown, so it is the only place deciding on a
+// replacement.
+func identityChanges(state, plan BackupDefinitionResourceModel) path.Paths {
+	var changed path.Paths
+
+	if !plan.Platform.Equal(state.Platform) {
+		changed = append(changed, path.Root("platform"))
+	}
+
+	if !plan.Account.Equal(state.Account) {
+		changed = append(changed, path.Root("account"))
+	}
+
+	if !plan.SubjectType.Equal(state.SubjectType) {
+		changed = append(changed, path.Root("subject_type"))
+	}
+
+	if !plan.SubjectName.Equal(state.SubjectName) {
+		changed = append(changed, path.Root("subject_name"))
+	}
+
+	return changed
+}
+
+// canonicalID returns the id of a definition in the form
+// platform/account/subject_type/subject_name, or an unknown value while any
+// part of it is unknown.
+func (m BackupDefinitionResourceModel) canonicalID() types.String {
+	parts := []types.String{m.Platform, m.Account, m.SubjectType, m.SubjectName}
+	values := make([]string, len(parts))
+
+	for i, part := range parts {
+		if part.IsUnknown() {
+			return types.StringUnknown()
+		}
+		values[i] = part.ValueString()
+	}
+
+	return types.StringValue(strings.Join(values, "/"))
+}
+
+// replacementWarning explains a planned replacement and its effect on the
+// definition of the previous subject.
+func replacementWarning(state, plan BackupDefinitionResourceModel, changed path.Paths) (string, string) {
+	return "Backup Definition Will Be Replaced",
+		fmt.Sprintf("Changing %s moves the backup definition from %s to %s. Terraform replaces it, and the definition of %s "+
+			"is handled according to its on_destroy = %q.",
+			changed, state.canonicalID().ValueString(), plan.canonicalID().ValueString(), state.canonicalID().ValueString(), state.OnDestroy.ValueString())
+}
+
+// subjectValidator requires the subject of a definition to be configured
+// either through the deprecated repository attribute or through both
+// subject_type and subject_name, reporting each mistake at the attribute
+// that has to change.
+type subjectValidator struct{}
+
+var _ resource.ConfigValidator = subjectValidator{}
+
+func (v subjectValidator) Description(ctx context.Context) string {
+	return "exactly one of repository or subject_type and subject_name must be configured, and subject_type and subject_name must be configured together"
+}
+
+func (v subjectValidator) MarkdownDescription(ctx context.Context) string {
+	return "exactly one of `repository` or `subject_type` and `subject_name` must be configured, and `subject_type` and `subject_name` must be configured together"
+}
+
+func (v subjectValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var repository, subjectType, subjectName types.String
+
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("repository"), &repository)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("subject_type"), &subjectType)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("subject_name"), &subjectName)...)
+
+	// Unknown values might still be null or set, so they are checked once
+	// they are known.
+	if resp.Diagnostics.HasError() || repository.IsUnknown() || subjectType.IsUnknown() || subjectName.IsUnknown() {
+		return
+	}
+
+	switch {
+	case !repository.IsNull() && (!subjectType.IsNull() || !subjectName.IsNull()):
+		resp.Diagnostics.AddAttributeError(
+			path.Root("repository"),
+			"Conflicting Subject Configuration",
+			"The deprecated repository attribute cannot be combined with subject_type and subject_name. "+
+				"Remove repository and set subject_type = \"Repository\" and subject_name instead.",
+		)
+	case !subjectType.IsNull() && subjectName.IsNull():
+		resp.Diagnostics.AddAttributeError(
+			path.Root("subject_name"),
+			"Missing Subject Configuration",
+			"The subject_name attribute must be set together with subject_type.",
+		)
+	case subjectType.IsNull() && !subjectName.IsNull():
+		resp.Diagnostics.AddAttributeError(
+			path.Root("subject_type"),
+			"Missing Subject Configuration",
+			"The subject_type attribute must be set together with subject_name.",
+		)
+	case repository.IsNull() && subjectType.IsNull() && subjectName.IsNull():
+		resp.Diagnostics.AddAttributeError(
+			path.Root("subject_name"),
+			"Missing Subject Configuration",
+			"Either both subject_type and subject_name, or the deprecated repository attribute, must be set.",
+		)
+	}
+}
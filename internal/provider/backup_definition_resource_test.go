@@ -1,13 +1,36 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
 	"testing"
+	"time"
 
+	"terraform-provider-cloudback/cloudbacktest"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
 )
 
 func TestAccBackupDefinitionResource(t *testing.T) {
 	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccCassette(t) },
 		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			// Create and Read testing
@@ -29,6 +52,7 @@ resource "cloudback_backup_definition" "test" {
 					resource.TestCheckResourceAttr("cloudback_backup_definition.test", "platform", "GitHub"),
 					resource.TestCheckResourceAttr("cloudback_backup_definition.test", "account", "testland"),
 					resource.TestCheckResourceAttr("cloudback_backup_definition.test", "repository", "docs"),
+					resource.TestCheckResourceAttr("cloudback_backup_definition.test", "id", "GitHub/testland/Repository/docs"),
 					resource.TestCheckResourceAttr("cloudback_backup_definition.test", "settings.enabled", "true"),
 					resource.TestCheckResourceAttr("cloudback_backup_definition.test", "settings.schedule", "Daily at 9 pm"),
 					resource.TestCheckResourceAttr("cloudback_backup_definition.test", "settings.storage", "Cloudback EU"),
@@ -37,11 +61,10 @@ resource "cloudback_backup_definition" "test" {
 			},
 			// ImportState testing
 			{
-				ResourceName:                         "cloudback_backup_definition.test",
-				ImportState:                          true,
-				ImportStateId:                        "GitHub/testland/docs",
-				ImportStateVerify:                    true,
-				ImportStateVerifyIdentifierAttribute: "repository",
+				ResourceName:      "cloudback_backup_definition.test",
+				ImportState:       true,
+				ImportStateId:     "GitHub/testland/docs",
+				ImportStateVerify: true,
 			},
 			// Update and Read testing
 			{
@@ -72,6 +95,7 @@ resource "cloudback_backup_definition" "test" {
 
 func TestAccBackupDefinitionResourceWithSubjectFields(t *testing.T) {
 	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccCassette(t) },
 		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			// Create and Read testing with subject_type and subject_name
@@ -124,3 +148,843 @@ resource "cloudback_backup_definition" "test_subject" {
 		},
 	})
 }
+
+func TestAccBackupDefinitionResourceRemovedOutsideTerraform(t *testing.T) {
+	var server *cloudbacktest.Server
+
+	config := providerConfig + `
+resource "cloudback_backup_definition" "test" {
+  platform = "GitHub"
+  account = "testland"
+  subject_type = "Repository"
+  subject_name = "removed"
+  settings = {
+    enabled = true
+    schedule = "Daily at 9 pm"
+    storage = "Cloudback EU"
+    retention = "Last 30 days"
+  }
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { server = testAccPreCheckFake(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			// Refresh drops the definition from state and plans to re-create it.
+			{
+				PreConfig: func() {
+					server.DeleteDefinition(cloudbacktest.Key{
+						Platform:    "GitHub",
+						Account:     "testland",
+						SubjectType: "Repository",
+						SubjectName: "removed",
+					})
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccBackupDefinitionResourceTimeout(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			server := testAccPreCheckFake(t)
+
+			server.InjectFault(cloudbacktest.Fault{
+				Path:       "/ops/definition/update",
+				StatusCode: http.StatusOK,
+				Delay:      5 * time.Second,
+			})
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "cloudback_backup_definition" "test" {
+  platform = "GitHub"
+  account = "testland"
+  subject_type = "Repository"
+  subject_name = "slow"
+  settings = {
+    enabled = true
+    schedule = "Daily at 9 pm"
+    storage = "Cloudback EU"
+    retention = "Last 30 days"
+  }
+  timeouts {
+    create = "500ms"
+  }
+}
+`,
+				ExpectError: regexp.MustCompile("Operation Timed Out"),
+			},
+		},
+	})
+}
+
+func TestAccBackupDefinitionResourceDefaultSettings(t *testing.T) {
+	config := func(schedule string) string {
+		return `
+provider "cloudback" {
+  default_settings {
+    enabled   = true
+    schedule  = "` + schedule + `"
+    storage   = "Cloudback EU"
+    retention = "Last 30 days"
+  }
+}
+
+resource "cloudback_backup_definition" "test" {
+  platform = "GitHub"
+  account = "testland"
+  subject_type = "Repository"
+  subject_name = "defaulted"
+  settings = {
+    retention = "Last 90 days"
+  }
+}
+`
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheckFake(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("Daily at 9 pm"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudback_backup_definition.test", "settings.enabled", "true"),
+					resource.TestCheckResourceAttr("cloudback_backup_definition.test", "settings.schedule", "Daily at 9 pm"),
+					resource.TestCheckResourceAttr("cloudback_backup_definition.test", "settings.storage", "Cloudback EU"),
+					resource.TestCheckResourceAttr("cloudback_backup_definition.test", "settings.retention", "Last 90 days"),
+				),
+			},
+			// A changed default updates the definition in place.
+			{
+				Config: config("Daily at 6 am"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("cloudback_backup_definition.test", plancheck.ResourceActionUpdate),
+						plancheck.ExpectKnownValue("cloudback_backup_definition.test", tfjsonpath.New("settings").AtMapKey("schedule"), knownvalue.StringExact("Daily at 6 am")),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("cloudback_backup_definition.test", "settings.schedule", "Daily at 6 am"),
+			},
+		},
+	})
+}
+
+// Settings built from the outputs of other resources are unknown while
+// planning, the defaults are filled in once they are known.
+func TestAccBackupDefinitionResourceUnknownSettings(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheckFake(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "cloudback" {
+  default_settings {
+    enabled   = true
+    schedule  = "Daily at 9 pm"
+    storage   = "Cloudback EU"
+    retention = "Last 30 days"
+  }
+}
+
+resource "terraform_data" "settings" {
+  input = {
+    retention = "Last 90 days"
+  }
+}
+
+resource "cloudback_backup_definition" "test" {
+  platform = "GitHub"
+  account = "testland"
+  subject_type = "Repository"
+  subject_name = "unknown-settings"
+  settings = terraform_data.settings.output
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectUnknownValue("cloudback_backup_definition.test", tfjsonpath.New("settings")),
+						plancheck.ExpectKnownValue("cloudback_backup_definition.test", tfjsonpath.New("id"), knownvalue.StringExact("GitHub/testland/Repository/unknown-settings")),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudback_backup_definition.test", "settings.schedule", "Daily at 9 pm"),
+					resource.TestCheckResourceAttr("cloudback_backup_definition.test", "settings.retention", "Last 90 days"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccBackupDefinitionResourceMissingSetting(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheckFake(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "cloudback_backup_definition" "test" {
+  platform = "GitHub"
+  account = "testland"
+  subject_type = "Repository"
+  subject_name = "incomplete"
+  settings = {
+    enabled = true
+    schedule = "Daily at 9 pm"
+    storage = "Cloudback EU"
+  }
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("Missing Attribute Value"),
+			},
+		},
+	})
+}
+
+func TestAccBackupDefinitionResourceDefaultPlatformAndAccount(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheckFake(t)
+
+			t.Setenv("CLOUDBACK_DEFAULT_PLATFORM", "GitHub")
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "cloudback" {
+  default_account = "testland"
+}
+
+resource "cloudback_backup_definition" "test" {
+  subject_type = "Repository"
+  subject_name = "implicit"
+  settings = {
+    enabled = true
+    schedule = "Daily at 9 pm"
+    storage = "Cloudback EU"
+    retention = "Last 30 days"
+  }
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudback_backup_definition.test", "platform", "GitHub"),
+					resource.TestCheckResourceAttr("cloudback_backup_definition.test", "account", "testland"),
+				),
+			},
+			// The resolved values are kept in state, so the import matches.
+			{
+				ResourceName:            "cloudback_backup_definition.test",
+				ImportState:             true,
+				ImportStateId:           "GitHub/testland/Repository/implicit",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"timeouts"},
+			},
+			// Values filled in from the defaults do not force a replacement
+			// when other attributes change.
+			{
+				Config: `
+provider "cloudback" {
+  default_account = "testland"
+}
+
+resource "cloudback_backup_definition" "test" {
+  subject_type = "Repository"
+  subject_name = "implicit"
+  settings = {
+    enabled = false
+    schedule = "Daily at 9 pm"
+    storage = "Cloudback EU"
+    retention = "Last 30 days"
+  }
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("cloudback_backup_definition.test", plancheck.ResourceActionUpdate),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestAccBackupDefinitionResourceOnDestroy(t *testing.T) {
+	key := cloudbacktest.Key{
+		Platform:    "GitHub",
+		Account:     "testland",
+		SubjectType: "Repository",
+		SubjectName: "destroyed",
+	}
+
+	tests := map[string]func(definition cloudbacktest.Definition, exists bool) error{
+		"disable": func(definition cloudbacktest.Definition, exists bool) error {
+			if !exists || definition.Settings.Enabled || definition.Settings.Schedule != "Daily at 9 pm" {
+				return fmt.Errorf("expected a paused definition keeping its settings, got %+v (exists: %t)", definition, exists)
+			}
+			return nil
+		},
+		"delete": func(definition cloudbacktest.Definition, exists bool) error {
+			if exists {
+				return fmt.Errorf("expected the definition to be deleted, got %+v", definition)
+			}
+			return nil
+		},
+		"abandon": func(definition cloudbacktest.Definition, exists bool) error {
+			if !exists || !definition.Settings.Enabled {
+				return fmt.Errorf("expected an untouched definition, got %+v (exists: %t)", definition, exists)
+			}
+			return nil
+		},
+	}
+
+	for mode, check := range tests {
+		t.Run(mode, func(t *testing.T) {
+			var server *cloudbacktest.Server
+
+			resource.Test(t, resource.TestCase{
+				PreCheck:                 func() { server = testAccPreCheckFake(t) },
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				CheckDestroy: func(*terraform.State) error {
+					definition, exists := server.GetDefinition(key)
+					return check(definition, exists)
+				},
+				Steps: []resource.TestStep{
+					{
+						Config: providerConfig + fmt.Sprintf(`
+resource "cloudback_backup_definition" "test" {
+  platform = "GitHub"
+  account = "testland"
+  subject_type = "Repository"
+  subject_name = "destroyed"
+  on_destroy = %q
+  settings = {
+    enabled = true
+    schedule = "Daily at 9 pm"
+    storage = "Cloudback EU"
+    retention = "Last 30 days"
+  }
+}
+`, mode),
+						Check: resource.TestCheckResourceAttr("cloudback_backup_definition.test", "on_destroy", mode),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestAccBackupDefinitionResourceReplace(t *testing.T) {
+	var server *cloudbacktest.Server
+
+	config := func(subject string) string {
+		return providerConfig + fmt.Sprintf(`
+resource "cloudback_backup_definition" "test" {
+  platform = "GitHub"
+  account = "testland"
+  %s
+  settings = {
+    enabled = true
+    schedule = "Daily at 9 pm"
+    storage = "Cloudback EU"
+    retention = "Last 30 days"
+  }
+}
+`, subject)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { server = testAccPreCheckFake(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config(`subject_type = "Repository"
+  subject_name = "before"`),
+				Check: resource.TestCheckResourceAttr("cloudback_backup_definition.test", "id", "GitHub/testland/Repository/before"),
+			},
+			// Moving to another subject replaces the definition and disables
+			// the previous one.
+			{
+				Config: config(`subject_type = "Repository"
+  subject_name = "after"`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("cloudback_backup_definition.test", plancheck.ResourceActionDestroyBeforeCreate),
+						plancheck.ExpectKnownValue("cloudback_backup_definition.test", tfjsonpath.New("id"), knownvalue.StringExact("GitHub/testland/Repository/after")),
+					},
+				},
+				Check: func(*terraform.State) error {
+					definition, exists := server.GetDefinition(cloudbacktest.Key{
+						Platform:    "GitHub",
+						Account:     "testland",
+						SubjectType: "Repository",
+						SubjectName: "before",
+					})
+					if !exists || definition.Settings.Enabled {
+						return fmt.Errorf("expected the previous definition to be disabled, got %+v (exists: %t)", definition, exists)
+					}
+					return nil
+				},
+			},
+			// The deprecated repository attribute naming the same subject is
+			// an in-place update.
+			{
+				Config: config(`repository = "after"`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("cloudback_backup_definition.test", plancheck.ResourceActionUpdate),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestBackupDefinitionResourceUpgradeStateV0(t *testing.T) {
+	ctx := context.Background()
+	r := &BackupDefinitionResource{}
+
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+
+	upgrader := r.UpgradeState(ctx)[0]
+
+	// State of provider versions that only knew the repository attribute.
+	rawState := tfprotov6.RawState{JSON: []byte(`{
+  "platform": "GitHub",
+  "account": "testland",
+  "repository": "docs",
+  "subject_type": null,
+  "subject_name": null,
+  "settings": {"enabled": true, "schedule": "Daily at 9 pm", "storage": "Cloudback EU", "retention": "Last 30 days"}
+}`)}
+
+	priorState, err := rawState.UnmarshalWithOpts(upgrader.PriorSchema.Type().TerraformType(ctx), tfprotov6.UnmarshalOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := fwresource.UpgradeStateResponse{State: tfsdk.State{Schema: schemaResp.Schema}}
+	upgrader.StateUpgrader(ctx, fwresource.UpgradeStateRequest{
+		State: &tfsdk.State{Raw: priorState, Schema: *upgrader.PriorSchema},
+	}, &resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	var data BackupDefinitionResourceModel
+	resp.Diagnostics.Append(resp.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	want := map[string]types.String{
+		"id":           types.StringValue("GitHub/testland/Repository/docs"),
+		"subject_type": types.StringValue("Repository"),
+		"subject_name": types.StringValue("docs"),
+		"repository":   types.StringValue("docs"),
+		"on_destroy":   types.StringValue(onDestroyDisable),
+		"schedule":     types.StringValue("Daily at 9 pm"),
+	}
+	got := map[string]types.String{
+		"id":           data.ID,
+		"subject_type": data.SubjectType,
+		"subject_name": data.SubjectName,
+		"repository":   data.Repository,
+		"on_destroy":   data.OnDestroy,
+		"schedule":     data.Settings.Schedule,
+	}
+
+	for name, value := range want {
+		if !got[name].Equal(value) {
+			t.Errorf("expected %s %s, got %s", name, value, got[name])
+		}
+	}
+}
+
+// legacyProvider serves the backup definition resource the way releases
+// before schema version 1 stored it.
+type legacyProvider struct {
+	*CloudbackProvider
+}
+
+func (p *legacyProvider) Resources(ctx context.Context) []func() fwresource.Resource {
+	return []func() fwresource.Resource{
+		func() fwresource.Resource { return &legacyBackupDefinitionResource{} },
+	}
+}
+
+// legacyBackupDefinitionResource writes schema version 0 state: definitions
+// configured with repository leave subject_type, subject_name and id null.
+type legacyBackupDefinitionResource struct {
+	BackupDefinitionResource
+}
+
+var legacyNullAttributes = []string{"id", "subject_type", "subject_name"}
+
+func (r *legacyBackupDefinitionResource) Schema(ctx context.Context, req fwresource.SchemaRequest, resp *fwresource.SchemaResponse) {
+	r.BackupDefinitionResource.Schema(ctx, req, resp)
+	resp.Schema.Version = 0
+}
+
+func (r *legacyBackupDefinitionResource) ModifyPlan(ctx context.Context, req fwresource.ModifyPlanRequest, resp *fwresource.ModifyPlanResponse) {
+	r.BackupDefinitionResource.ModifyPlan(ctx, req, resp)
+
+	if resp.Diagnostics.HasError() || resp.Plan.Raw.IsNull() {
+		return
+	}
+
+	for _, name := range legacyNullAttributes {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root(name), types.StringNull())...)
+	}
+}
+
+func (r *legacyBackupDefinitionResource) Create(ctx context.Context, req fwresource.CreateRequest, resp *fwresource.CreateResponse) {
+	var repository types.String
+
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("repository"), &repository)...)
+	resp.Diagnostics.Append(req.Plan.SetAttribute(ctx, path.Root("subject_type"), repositorySubjectType)...)
+	resp.Diagnostics.Append(req.Plan.SetAttribute(ctx, path.Root("subject_name"), repository)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.BackupDefinitionResource.Create(ctx, req, resp)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, name := range legacyNullAttributes {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root(name), types.StringNull())...)
+	}
+}
+
+// Read keeps the prior state, the subject needed to look it up is missing.
+func (r *legacyBackupDefinitionResource) Read(ctx context.Context, req fwresource.ReadRequest, resp *fwresource.ReadResponse) {
+}
+
+func TestAccBackupDefinitionResourceUpgradeStateV0(t *testing.T) {
+	config := providerConfig + `
+resource "cloudback_backup_definition" "test" {
+  platform = "GitHub"
+  account = "testland"
+  repository = "docs"
+  settings = {
+    enabled = true
+    schedule = "Daily at 9 pm"
+    storage = "Cloudback EU"
+    retention = "Last 30 days"
+  }
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheckFake(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+					"cloudback": providerserver.NewProtocol6WithError(&legacyProvider{CloudbackProvider: &CloudbackProvider{version: "test"}}),
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudback_backup_definition.test", "repository", "docs"),
+					resource.TestCheckNoResourceAttr("cloudback_backup_definition.test", "subject_name"),
+				),
+			},
+			// Upgrading the provider keeps the definition as it is.
+			{
+				Config:                   config,
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudback_backup_definition.test", "id", "GitHub/testland/Repository/docs"),
+					resource.TestCheckResourceAttr("cloudback_backup_definition.test", "subject_type", "Repository"),
+					resource.TestCheckResourceAttr("cloudback_backup_definition.test", "subject_name", "docs"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccBackupDefinitionResourceInvalidSubject(t *testing.T) {
+	tests := map[string]struct {
+		subject string
+		err     string
+	}{
+		"none": {
+			err: `(?s)Missing Subject Configuration.*Either both subject_type and subject_name`,
+		},
+		"subject_type only": {
+			subject: `subject_type = "Repository"`,
+			err:     `(?s)Missing Subject Configuration.*subject_name attribute must be set together with subject_type`,
+		},
+		"subject_name only": {
+			subject: `subject_name = "docs"`,
+			err:     `(?s)Missing Subject Configuration.*subject_type attribute must be set together with subject_name`,
+		},
+		"repository and subject": {
+			subject: `repository = "docs"
+  subject_type = "Repository"
+  subject_name = "docs"`,
+			err: `Conflicting Subject Configuration`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resource.Test(t, resource.TestCase{
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps: []resource.TestStep{
+					{
+						Config: fmt.Sprintf(`
+resource "cloudback_backup_definition" "test" {
+  platform = "GitHub"
+  account = "testland"
+  %s
+  settings = {
+    enabled = true
+    schedule = "Daily at 9 pm"
+    storage = "Cloudback EU"
+    retention = "Last 30 days"
+  }
+}
+`, test.subject),
+						PlanOnly:    true,
+						ExpectError: regexp.MustCompile(test.err),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestAddClientErrorTimeouts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server notices the client giving up only once the body has
+		// been read.
+		_, _ = io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	retry := DefaultRetryConfig()
+	retry.MaxAttempts = 2
+	retry.MinBackoff = time.Millisecond
+	retry.MaxBackoff = time.Millisecond
+
+	tests := map[string]struct {
+		requestTimeout   time.Duration
+		operationTimeout time.Duration
+		summary          string
+	}{
+		"request timeout": {
+			requestTimeout:   20 * time.Millisecond,
+			operationTimeout: time.Minute,
+			summary:          "Request Timed Out",
+		},
+		"operation timeout": {
+			requestTimeout:   time.Minute,
+			operationTimeout: 20 * time.Millisecond,
+			summary:          "Operation Timed Out",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), test.operationTimeout)
+			defer cancel()
+
+			client := NewCloudbackClient(server.URL, "test-key", WithRetryConfig(retry), WithRequestTimeout(test.requestTimeout))
+			_, err := client.GetBackupDefinition(ctx, "GitHub", "testland", "Repository", "docs")
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+
+			var diags diag.Diagnostics
+			addClientError(ctx, &diags, BackupDefinitionResourceModel{}, "Unable to read backup definition", err)
+
+			if len(diags) != 1 || diags[0].Summary() != test.summary {
+				t.Errorf("expected a %q diagnostic, got %v", test.summary, diags)
+			}
+		})
+	}
+}
+
+// testBackupDefinitionValue returns a backup definition with the given
+// attribute values and settings, all other attributes are null.
+func testBackupDefinitionValue(t *testing.T, values map[string]string, enabled bool) (tftypes.Type, tftypes.Value) {
+	t.Helper()
+
+	var schemaResp fwresource.SchemaResponse
+	(&BackupDefinitionResource{}).Schema(context.Background(), fwresource.SchemaRequest{}, &schemaResp)
+
+	objectType, ok := schemaResp.Schema.Type().TerraformType(context.Background()).(tftypes.Object)
+	if !ok {
+		t.Fatal("expected the resource schema to be an object")
+	}
+
+	attributes := map[string]tftypes.Value{}
+	for name, attributeType := range objectType.AttributeTypes {
+		attributes[name] = tftypes.NewValue(attributeType, nil)
+		if value, ok := values[name]; ok {
+			attributes[name] = tftypes.NewValue(tftypes.String, value)
+		}
+	}
+
+	settingsType := objectType.AttributeTypes["settings"]
+	attributes["settings"] = tftypes.NewValue(settingsType, map[string]tftypes.Value{
+		"enabled":   tftypes.NewValue(tftypes.Bool, enabled),
+		"schedule":  tftypes.NewValue(tftypes.String, "Daily at 9 pm"),
+		"storage":   tftypes.NewValue(tftypes.String, "Cloudback EU"),
+		"retention": tftypes.NewValue(tftypes.String, "Last 30 days"),
+	})
+
+	return objectType, tftypes.NewValue(objectType, attributes)
+}
+
+func TestBackupDefinitionResourcePlanRequiresReplace(t *testing.T) {
+	ctx := context.Background()
+
+	// Terraform proposes the prior state for computed attributes left null
+	// in the configuration.
+	proposed := func(config, state map[string]string) map[string]string {
+		values := map[string]string{}
+		for name, value := range state {
+			values[name] = value
+		}
+		for name, value := range config {
+			values[name] = value
+		}
+		return values
+	}
+
+	defaultedState := map[string]string{
+		"id":           "GitHub/testland/Repository/docs",
+		"platform":     "GitHub",
+		"account":      "testland",
+		"subject_type": "Repository",
+		"subject_name": "docs",
+		"on_destroy":   "disable",
+	}
+	repositoryState := map[string]string{
+		"id":           "GitHub/testland/Repository/docs",
+		"platform":     "GitHub",
+		"account":      "testland",
+		"subject_type": "Repository",
+		"subject_name": "docs",
+		"repository":   "docs",
+		"on_destroy":   "delete",
+	}
+
+	tests := map[string]struct {
+		state   map[string]string
+		config  map[string]string
+		enabled bool
+		replace []string
+	}{
+		"settings change with defaulted platform and account": {
+			state:  defaultedState,
+			config: map[string]string{"subject_type": "Repository", "subject_name": "docs"},
+		},
+		"settings change with repository": {
+			state:  repositoryState,
+			config: map[string]string{"platform": "GitHub", "account": "testland", "repository": "docs", "on_destroy": "delete"},
+		},
+		"repository moved to subject fields": {
+			state:   repositoryState,
+			config:  map[string]string{"platform": "GitHub", "account": "testland", "subject_type": "Repository", "subject_name": "docs", "on_destroy": "delete"},
+			enabled: true,
+		},
+		"subject change": {
+			state:   defaultedState,
+			config:  map[string]string{"subject_type": "Repository", "subject_name": "other"},
+			replace: []string{"subject_name"},
+		},
+		"default platform change": {
+			state:   map[string]string{"id": "GitLab/testland/Repository/docs", "platform": "GitLab", "account": "testland", "subject_type": "Repository", "subject_name": "docs", "on_destroy": "disable"},
+			config:  map[string]string{"subject_type": "Repository", "subject_name": "docs"},
+			enabled: true,
+			replace: []string{"platform"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server, err := providerserver.NewProtocol6WithError(New("test")())()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			providerConfig := testProviderConfig(t, map[string]tftypes.Value{
+				"api_key":                     tftypes.NewValue(tftypes.String, "test-api-key"),
+				"skip_credentials_validation": tftypes.NewValue(tftypes.Bool, true),
+				"default_platform":            tftypes.NewValue(tftypes.String, "GitHub"),
+				"default_account":             tftypes.NewValue(tftypes.String, "testland"),
+			})
+			providerConfigValue, err := tfprotov6.NewDynamicValue(providerConfig.Raw.Type(), providerConfig.Raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			configureResp, err := server.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{Config: &providerConfigValue})
+			if err != nil || len(configureResp.Diagnostics) > 0 {
+				t.Fatalf("unable to configure the provider: %v %v", err, configureResp.Diagnostics)
+			}
+
+			dynamicValue := func(values map[string]string, enabled bool) *tfprotov6.DynamicValue {
+				objectType, value := testBackupDefinitionValue(t, values, enabled)
+				dynamicValue, err := tfprotov6.NewDynamicValue(objectType, value)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return &dynamicValue
+			}
+
+			resp, err := server.PlanResourceChange(ctx, &tfprotov6.PlanResourceChangeRequest{
+				TypeName:         "cloudback_backup_definition",
+				PriorState:       dynamicValue(test.state, true),
+				ProposedNewState: dynamicValue(proposed(test.config, test.state), test.enabled),
+				Config:           dynamicValue(test.config, test.enabled),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, diagnostic := range resp.Diagnostics {
+				if diagnostic.Severity == tfprotov6.DiagnosticSeverityError {
+					t.Fatalf("unexpected error: %s: %s", diagnostic.Summary, diagnostic.Detail)
+				}
+			}
+
+			var replace []*tftypes.AttributePath
+			for _, name := range test.replace {
+				replace = append(replace, tftypes.NewAttributePath().WithAttributeName(name))
+			}
+
+			if !reflect.DeepEqual(resp.RequiresReplace, replace) {
+				t.Errorf("expected RequiresReplace %v, got %v", replace, resp.RequiresReplace)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// backupDefinitionSchemaVersion is the version of the backup definition
+// schema. Version 1 always stores subject_type and subject_name, also for
+// definitions configured through the deprecated repository attribute.
+const backupDefinitionSchemaVersion = 1
+
+func (r *BackupDefinitionResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema:   backupDefinitionSchemaV0(ctx),
+			StateUpgrader: upgradeBackupDefinitionStateV0,
+		},
+	}
+}
+
+// backupDefinitionSchemaV0 describes the attributes state of version 0 may
+// hold. Only the types matter for reading the prior state.
+func backupDefinitionSchemaV0(ctx context.Context) *schema.Schema {
+	return &schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id":           schema.StringAttribute{Computed: true},
+			"platform":     schema.StringAttribute{Optional: true},
+			"account":      schema.StringAttribute{Optional: true},
+			"subject_type": schema.StringAttribute{Optional: true},
+			"subject_name": schema.StringAttribute{Optional: true},
+			"repository":   schema.StringAttribute{Optional: true},
+			"on_destroy":   schema.StringAttribute{Optional: true},
+			"settings": schema.SingleNestedAttribute{
+				Required: true,
+				Attributes: map[string]schema.Attribute{
+					"enabled":   schema.BoolAttribute{Optional: true},
+					"schedule":  schema.StringAttribute{Optional: true},
+					"storage":   schema.StringAttribute{Optional: true},
+					"retention": schema.StringAttribute{Optional: true},
+				},
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+// upgradeBackupDefinitionStateV0 rewrites definitions stored with only the
+// repository attribute to subject_type Repository and subject_name. The
+// repository attribute is kept, so configurations still using it plan no
+// changes.
+func upgradeBackupDefinitionStateV0(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	var data BackupDefinitionResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.Repository.IsNull() && data.SubjectType.IsNull() && data.SubjectName.IsNull() {
+		data.SubjectType = types.StringValue(repositorySubjectType)
+		data.SubjectName = data.Repository
+	}
+
+	// State written before on_destroy and id existed.
+	if data.OnDestroy.IsNull() {
+		data.OnDestroy = types.StringValue(onDestroyDisable)
+	}
+	data.ID = data.canonicalID()
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -1,15 +1,42 @@
 package provider
 
 import (
+	"context"
+	"crypto/tls"
+
 	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 type CloudbackClient struct {
 	restyClient *resty.Client
 	Endpoint    string
 	ApiKey      string
+
+	credentialProcess    *CredentialProcess
+	retryableStatusCodes map[int]bool
+	readOnly             bool
 }
 
+// requestKind tells post whether a request changes anything and whether it
+// may be replayed.
+type requestKind int
+
+const (
+	// readRequest has no side effects. It is retried and allowed in
+	// read-only mode.
+	readRequest requestKind = iota
+	// idempotentWrite changes data, but replaying it leaves the same result
+	// behind. It is retried and refused in read-only mode.
+	idempotentWrite
+	// write changes data and must not be replayed. It is refused in
+	// read-only mode.
+	write
+	// probeRequest has no side effects and is made once, so that a check
+	// of the configuration fails fast. It is allowed in read-only mode.
+	probeRequest
+)
+
 type BackupDefinition struct {
 	Platform    string                   `json:"platform"`
 	Account     string                   `json:"account"`
@@ -25,55 +52,149 @@ type BackupDefinitionSettings struct {
 	Retention string `json:"retention"`
 }
 
-func NewCloudbackClient(baseURL, apiKey string) *CloudbackClient {
+func NewCloudbackClient(baseURL, apiKey string, opts ...ClientOption) *CloudbackClient {
+	options := defaultClientOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	client := resty.New()
+
+	// The TLS and proxy settings apply to the *http.Transport created by
+	// resty, so they must be set before it is wrapped below.
+	client.SetTLSClientConfig(&tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		RootCAs:            options.rootCAs,
+		Certificates:       options.certificates,
+		InsecureSkipVerify: options.insecureSkipVerify,
+	})
+	if options.proxyURL != nil {
+		client.SetProxy(options.proxyURL.String())
+	}
+
+	client.SetTransport(newLimitedTransport(
+		&loggingTransport{next: cassetteTransportFromEnv(client.GetClient().Transport, apiKey)},
+		options.maxConcurrentRequests,
+	))
+	if options.requestsPerSecond > 0 {
+		client.OnBeforeRequest(rateLimit(options.requestsPerSecond))
+	}
+	client.SetHeaders(options.headers)
 	client.SetHeader("Content-Type", "application/json")
 	client.SetHeader("X-API-KEY", apiKey)
+	if options.userAgent != "" {
+		client.SetHeader("User-Agent", options.userAgent)
+	}
+	if options.credentialProcess != nil {
+		client.OnBeforeRequest(options.credentialProcess.setAPIKey)
+	}
 	client.SetBaseURL(baseURL)
+	client.SetTimeout(options.requestTimeout)
 	client.SetDebug(false)
+	// Requests replace the logger with one carrying their log context.
+	// Messages logged outside of a request have none and are dropped.
+	client.SetLogger(restyLogger{ctx: context.Background()})
 
-	return &CloudbackClient{
+	c := &CloudbackClient{
 		restyClient: client,
 		Endpoint:    baseURL,
 		ApiKey:      apiKey,
+
+		credentialProcess: options.credentialProcess,
+		readOnly:          options.readOnly,
 	}
+	c.configureRetries(options.retry)
+
+	return c
 }
 
-func (c *CloudbackClient) GetBackupDefinition(platform, account, subjectType, subjectName string) (*BackupDefinition, error) {
+func (c *CloudbackClient) GetBackupDefinition(ctx context.Context, platform, account, subjectType, subjectName string) (*BackupDefinition, error) {
 
 	var response BackupDefinition
 
-	resp, err := c.restyClient.R().
-		SetBody(map[string]string{
-			"platform":    platform,
-			"account":     account,
-			"subjectType": subjectType,
-			"subjectName": subjectName,
-		}).
-		SetResult(&response).
-		Post("/ops/definition/get")
+	ctx = withSubjectFields(ctx, platform, account, subjectType, subjectName)
+
+	err := c.post(ctx, "/ops/definition/get", map[string]string{
+		"platform":    platform,
+		"account":     account,
+		"subjectType": subjectType,
+		"subjectName": subjectName,
+	}, &response, readRequest)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.IsError() {
-		return nil, NewAPIError(resp)
-	}
-
 	return &response, nil
 }
 
-func (c *CloudbackClient) UpdateBackupDefinition(platform, account, subjectType, subjectName string, settings BackupDefinitionSettings) error {
-	resp, err := c.restyClient.R().
-		SetBody(&BackupDefinition{
-			Platform:    platform,
-			Account:     account,
-			SubjectType: subjectType,
-			SubjectName: subjectName,
-			Settings:    settings,
-		}).
-		Post("/ops/definition/update")
+// probe reads a definition without a subject in a single attempt. The API
+// answers with an error document, which still shows that the endpoint and
+// the API key work.
+func (c *CloudbackClient) probe(ctx context.Context) error {
+	return c.post(ctx, "/ops/definition/get", map[string]string{
+		"platform":    "",
+		"account":     "",
+		"subjectType": "",
+		"subjectName": "",
+	}, nil, probeRequest)
+}
+
+func (c *CloudbackClient) UpdateBackupDefinition(ctx context.Context, platform, account, subjectType, subjectName string, settings BackupDefinitionSettings) error {
+	ctx = withSubjectFields(ctx, platform, account, subjectType, subjectName)
+
+	// The update endpoint replaces the whole definition, so replaying it
+	// after a lost response leaves the same result behind.
+	return c.post(ctx, "/ops/definition/update", &BackupDefinition{
+		Platform:    platform,
+		Account:     account,
+		SubjectType: subjectType,
+		SubjectName: subjectName,
+		Settings:    settings,
+	}, nil, idempotentWrite)
+}
+
+// DeleteBackupDefinition removes a backup definition. Deleting a definition
+// that does not exist fails with ErrNotFound.
+func (c *CloudbackClient) DeleteBackupDefinition(ctx context.Context, platform, account, subjectType, subjectName string) error {
+	ctx = withSubjectFields(ctx, platform, account, subjectType, subjectName)
+
+	// Replaying a delete after a lost response fails with ErrNotFound,
+	// which callers treat as success.
+	return c.post(ctx, "/ops/definition/delete", map[string]string{
+		"platform":    platform,
+		"account":     account,
+		"subjectType": subjectType,
+		"subjectName": subjectName,
+	}, nil, idempotentWrite)
+}
+
+// post sends a JSON request to the Cloudback API and decodes the response
+// into result when it is not nil. Every client method goes through post so
+// that retries, read-only mode and error handling apply uniformly. Only
+// requests that are safe to replay are retried. Cancelling ctx aborts the
+// request in flight as well as any pending backoff.
+func (c *CloudbackClient) post(ctx context.Context, path string, body, result interface{}, kind requestKind) error {
+	if c.readOnly && kind != readRequest && kind != probeRequest {
+		return ErrReadOnly
+	}
+
+	retried := kind == readRequest || kind == idempotentWrite
+	logCtx := c.withHTTPLogging(ctx)
+
+	req := c.restyClient.R().
+		SetContext(logCtx).
+		SetLogger(restyLogger{ctx: logCtx}).
+		SetBody(body).
+		AddRetryCondition(func(resp *resty.Response, err error) bool {
+			return retried && c.shouldRetry(ctx, resp, err)
+		})
+
+	if result != nil {
+		req.SetResult(result)
+	}
+
+	resp, err := req.Post(path)
 
 	if err != nil {
 		return err
@@ -86,18 +207,14 @@ func (c *CloudbackClient) UpdateBackupDefinition(platform, account, subjectType,
 	return nil
 }
 
-func NewAPIError(resp *resty.Response) error {
-	return &APIError{
-		StatusCode: resp.StatusCode(),
-		Status:     resp.Status(),
-	}
-}
-
-type APIError struct {
-	StatusCode int
-	Status     string
-}
-
-func (e *APIError) Error() string {
-	return e.Status
+// withSubjectFields attaches the backup definition subject to ctx so that
+// every log line emitted while serving the request can be correlated with
+// the resource that triggered it.
+func withSubjectFields(ctx context.Context, platform, account, subjectType, subjectName string) context.Context {
+	return tflog.SetField(ctx, "subject", map[string]interface{}{
+		"platform":     platform,
+		"account":      account,
+		"subject_type": subjectType,
+		"subject_name": subjectName,
+	})
 }
@@ -0,0 +1,255 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Cassettes let acceptance tests run without network access. In record mode
+// every request is sent to the API and the scrubbed request/response pair
+// is appended to the cassette file; in replay mode the responses are served
+// from the file and the API is never contacted.
+//
+//	CLOUDBACK_CASSETTE=testdata/cassettes/example.json
+//	CLOUDBACK_CASSETTE_MODE=record|replay
+const (
+	cassetteEnvVar     = "CLOUDBACK_CASSETTE"
+	cassetteModeEnvVar = "CLOUDBACK_CASSETTE_MODE"
+
+	cassetteModeRecord = "record"
+	cassetteModeReplay = "replay"
+)
+
+// cassetteResponseHeaders are the response headers kept in a cassette, all
+// others are dropped when recording.
+var cassetteResponseHeaders = []string{
+	"Content-Type",
+	"Retry-After",
+	requestIDHeader,
+}
+
+type cassette struct {
+	mu           sync.Mutex
+	path         string
+	loadErr      error
+	Interactions []*interaction `json:"interactions"`
+}
+
+type interaction struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+
+	replayed bool
+}
+
+type recordedRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Body   string `json:"body"`
+}
+
+type recordedResponse struct {
+	StatusCode int                 `json:"status_code"`
+	Headers    map[string][]string `json:"headers,omitempty"`
+	Body       string              `json:"body"`
+}
+
+var (
+	cassettesMu sync.Mutex
+	// cassettes are shared by every client of the process, so that the
+	// position in the cassette survives the provider being configured again
+	// for each Terraform command.
+	cassettes = map[string]*cassette{}
+)
+
+// loadCassette returns the cassette stored at path, reading it on first
+// use. When recording, an existing file is ignored so that the recording
+// starts from a blank cassette.
+func loadCassette(path string, record bool) *cassette {
+	cassettesMu.Lock()
+	defer cassettesMu.Unlock()
+
+	if c, ok := cassettes[path]; ok {
+		return c
+	}
+
+	c := &cassette{path: path}
+
+	if !record {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			c.loadErr = err
+		} else {
+			c.loadErr = json.Unmarshal(data, c)
+		}
+	}
+
+	cassettes[path] = c
+
+	return c
+}
+
+// forgetCassette drops the cached state of the cassette at path, so that
+// it is read again from the start by the next client.
+func forgetCassette(path string) {
+	cassettesMu.Lock()
+	defer cassettesMu.Unlock()
+
+	delete(cassettes, path)
+}
+
+// cassetteTransportFromEnv wraps next with a recording or replaying
+// transport when CLOUDBACK_CASSETTE_MODE asks for it.
+func cassetteTransportFromEnv(next http.RoundTripper, apiKey string) http.RoundTripper {
+	mode, path := os.Getenv(cassetteModeEnvVar), os.Getenv(cassetteEnvVar)
+
+	if path == "" || (mode != cassetteModeRecord && mode != cassetteModeReplay) {
+		return next
+	}
+
+	return &cassetteTransport{
+		next:     next,
+		cassette: loadCassette(path, mode == cassetteModeRecord),
+		record:   mode == cassetteModeRecord,
+		apiKey:   apiKey,
+	}
+}
+
+type cassetteTransport struct {
+	next     http.RoundTripper
+	cassette *cassette
+	record   bool
+	apiKey   string
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded := recordedRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Body:   t.scrub(requestBody(req)),
+	}
+
+	if t.record {
+		return t.recordRoundTrip(req, recorded)
+	}
+
+	return t.cassette.replay(req, recorded)
+}
+
+func (t *cassetteTransport) recordRoundTrip(req *http.Request, recorded recordedRequest) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	headers := map[string][]string{}
+	for _, name := range cassetteResponseHeaders {
+		if values := resp.Header.Values(name); len(values) > 0 {
+			headers[name] = values
+		}
+	}
+
+	err = t.cassette.append(&interaction{
+		Request: recorded,
+		Response: recordedResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    headers,
+			Body:       t.scrub(string(body)),
+		},
+	})
+
+	return resp, err
+}
+
+// scrub removes the API key from recorded content.
+func (t *cassetteTransport) scrub(content string) string {
+	if t.apiKey == "" {
+		return content
+	}
+
+	return strings.ReplaceAll(content, t.apiKey, redactedValue)
+}
+
+func (c *cassette) append(i *interaction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Interactions = append(c.Interactions, i)
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.path, append(data, '\n'), 0o644)
+}
+
+// replay serves the first not yet replayed interaction matching the
+// request. Once all matching interactions were used, the last one is served
+// again, which keeps replays stable if Terraform refreshes more often than
+// during the recording.
+func (c *cassette) replay(req *http.Request, recorded recordedRequest) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.loadErr != nil {
+		return nil, fmt.Errorf("unable to load cassette %s: %w", c.path, c.loadErr)
+	}
+
+	var match *interaction
+
+	for _, i := range c.Interactions {
+		if i.Request != recorded {
+			continue
+		}
+
+		match = i
+		if !i.replayed {
+			break
+		}
+	}
+
+	if match == nil {
+		return nil, fmt.Errorf("cassette %s has no interaction for %s %s with body %s, record it again with %s=%s",
+			c.path, recorded.Method, recorded.Path, recorded.Body, cassetteModeEnvVar, cassetteModeRecord)
+	}
+
+	match.replayed = true
+
+	header := http.Header{}
+	for name, values := range match.Response.Headers {
+		for _, value := range values {
+			header.Add(name, value)
+		}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", match.Response.StatusCode, http.StatusText(match.Response.StatusCode)),
+		StatusCode:    match.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(match.Response.Body)),
+		ContentLength: int64(len(match.Response.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCloudbackClientRecordsAndReplaysCassettes(t *testing.T) {
+	cassettePath := filepath.Join(t.TempDir(), "cassette.json")
+	t.Setenv(cassetteEnvVar, cassettePath)
+	t.Cleanup(func() { forgetCassette(cassettePath) })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		_, _ = w.Write([]byte(`{"settings":{"enabled":true,"schedule":"Daily at 9 pm"}}`))
+	}))
+
+	t.Setenv(cassetteModeEnvVar, cassetteModeRecord)
+	recorder := NewCloudbackClient(server.URL, "super-secret-key")
+	if _, err := recorder.GetBackupDefinition(context.Background(), "GitHub", "testland", "Repository", "docs"); err != nil {
+		t.Fatalf("unexpected error while recording: %s", err)
+	}
+
+	server.Close()
+	forgetCassette(cassettePath)
+
+	data, err := os.ReadFile(cassettePath)
+	if err != nil {
+		t.Fatalf("unable to read cassette: %s", err)
+	}
+
+	for _, secret := range []string{"super-secret-key", "session=secret"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %q:\n%s", secret, data)
+		}
+	}
+
+	t.Setenv(cassetteModeEnvVar, cassetteModeReplay)
+	player := NewCloudbackClient(server.URL, "another-key")
+
+	definition, err := player.GetBackupDefinition(context.Background(), "GitHub", "testland", "Repository", "docs")
+	if err != nil {
+		t.Fatalf("unexpected error while replaying: %s", err)
+	}
+
+	if definition.Settings.Schedule != "Daily at 9 pm" {
+		t.Errorf("unexpected schedule %q", definition.Settings.Schedule)
+	}
+
+	if _, err := player.GetBackupDefinition(context.Background(), "GitHub", "testland", "Repository", "other"); err == nil {
+		t.Error("expected an unrecorded request to fail, got nil")
+	}
+}
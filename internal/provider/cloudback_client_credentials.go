@@ -0,0 +1,178 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// credentialExpiryWindow renews a credential this long before it expires, so
+// that it does not run out while a request is in flight.
+const credentialExpiryWindow = time.Minute
+
+// credentialProcessVersion is the only supported version of the credential
+// document.
+const credentialProcessVersion = 1
+
+// CredentialProcess obtains the API key from an external command, modeled on
+// the AWS credential_process setting. The command prints a JSON document to
+// stdout:
+//
+//	{"Version": 1, "ApiKey": "...", "Expiration": "2026-01-02T15:04:05Z"}
+//
+// Expiration is optional; a credential without one never expires. The
+// credential is cached and the command runs again once it has expired.
+type CredentialProcess struct {
+	command []string
+
+	mu         sync.Mutex
+	apiKey     string
+	expiration time.Time
+}
+
+type credentialDocument struct {
+	Version    int        `json:"Version"`
+	ApiKey     string     `json:"ApiKey"`
+	Expiration *time.Time `json:"Expiration"`
+}
+
+// NewCredentialProcess parses command into its arguments. Arguments are
+// separated by whitespace and may be quoted with single or double quotes.
+func NewCredentialProcess(command string) (*CredentialProcess, error) {
+	args, err := splitCommand(command)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) == 0 {
+		return nil, errors.New("the credential process command is empty")
+	}
+
+	return &CredentialProcess{command: args}, nil
+}
+
+// APIKey returns the cached API key, running the command when there is no
+// credential yet or it is about to expire.
+func (p *CredentialProcess) APIKey(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.apiKey != "" && (p.expiration.IsZero() || time.Until(p.expiration) > credentialExpiryWindow) {
+		return p.apiKey, nil
+	}
+
+	document, err := p.run(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	p.apiKey = document.ApiKey
+	p.expiration = time.Time{}
+	if document.Expiration != nil {
+		p.expiration = *document.Expiration
+	}
+
+	return p.apiKey, nil
+}
+
+// current returns the cached API key without running the command.
+func (p *CredentialProcess) current() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.apiKey
+}
+
+func (p *CredentialProcess) run(ctx context.Context) (*credentialDocument, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, p.command[0], p.command[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("credential process %s failed: %w: %s", p.command[0], err, message)
+		}
+		return nil, fmt.Errorf("credential process %s failed: %w", p.command[0], err)
+	}
+
+	// The output holds the API key, so decoding errors must not quote it.
+	var document credentialDocument
+	if err := json.Unmarshal(stdout.Bytes(), &document); err != nil {
+		return nil, fmt.Errorf("credential process %s printed an invalid credential document", p.command[0])
+	}
+
+	if document.Version != credentialProcessVersion {
+		return nil, fmt.Errorf("credential process %s printed an unsupported Version %d, expected %d", p.command[0], document.Version, credentialProcessVersion)
+	}
+
+	if document.ApiKey == "" {
+		return nil, fmt.Errorf("credential process %s printed no ApiKey", p.command[0])
+	}
+
+	return &document, nil
+}
+
+// setAPIKey is a resty middleware that sends the current API key of the
+// credential process. It runs before every attempt, so retries pick up a
+// renewed credential.
+func (p *CredentialProcess) setAPIKey(_ *resty.Client, req *resty.Request) error {
+	apiKey, err := p.APIKey(req.Context())
+	if err != nil {
+		return err
+	}
+
+	req.SetHeader("X-API-KEY", apiKey)
+
+	return nil
+}
+
+// splitCommand splits a command line into arguments, honoring single and
+// double quotes but no other shell syntax.
+func splitCommand(command string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+		quote   rune
+	)
+
+	for _, r := range command {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in command %q", quote, command)
+	}
+
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
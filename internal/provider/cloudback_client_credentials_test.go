@@ -0,0 +1,152 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const credentialHelperEnvVar = "CLOUDBACK_TEST_CREDENTIAL_HELPER"
+
+// TestCredentialProcessHelper is not a real test. It stands in for the
+// external command when the test binary is run as a credential process.
+func TestCredentialProcessHelper(t *testing.T) {
+	counterFile := os.Getenv(credentialHelperEnvVar)
+	if counterFile == "" {
+		return
+	}
+
+	if os.Getenv("CLOUDBACK_TEST_CREDENTIAL_FAIL") != "" {
+		fmt.Fprintln(os.Stderr, "vault is sealed")
+		os.Exit(1)
+	}
+
+	content, _ := os.ReadFile(counterFile)
+	runs, _ := strconv.Atoi(string(content))
+	runs++
+	_ = os.WriteFile(counterFile, []byte(strconv.Itoa(runs)), 0o600)
+
+	apiKey := os.Getenv("CLOUDBACK_TEST_CREDENTIAL_API_KEY")
+	if apiKey == "" {
+		apiKey = fmt.Sprintf("key-%d", runs)
+	}
+
+	lifetime, _ := time.ParseDuration(os.Getenv("CLOUDBACK_TEST_CREDENTIAL_LIFETIME"))
+	_ = json.NewEncoder(os.Stdout).Encode(map[string]any{
+		"Version":    1,
+		"ApiKey":     apiKey,
+		"Expiration": time.Now().Add(lifetime).Format(time.RFC3339),
+	})
+	os.Exit(0)
+}
+
+// newTestCredentialProcess returns a credential process running the test
+// binary, whose credentials expire after lifetime.
+func newTestCredentialProcess(t *testing.T, lifetime time.Duration) *CredentialProcess {
+	t.Setenv(credentialHelperEnvVar, filepath.Join(t.TempDir(), "runs"))
+	t.Setenv("CLOUDBACK_TEST_CREDENTIAL_LIFETIME", lifetime.String())
+
+	process, err := NewCredentialProcess(fmt.Sprintf("%q -test.run=^TestCredentialProcessHelper$", os.Args[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return process
+}
+
+func TestCredentialProcessRenewsExpiredKeys(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		apiKeys []string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		apiKeys = append(apiKeys, r.Header.Get("X-API-KEY"))
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(BackupDefinition{})
+	}))
+	defer server.Close()
+
+	// The credential expires within the renewal window, so every request
+	// runs the process again.
+	process := newTestCredentialProcess(t, 30*time.Second)
+
+	apiKey, err := process.APIKey(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	client := NewCloudbackClient(server.URL, apiKey, WithCredentialProcess(process))
+	for range 2 {
+		if _, err := client.GetBackupDefinition(context.Background(), "GitHub", "testland", "Repository", "docs"); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	if want := []string{"key-2", "key-3"}; !reflect.DeepEqual(apiKeys, want) {
+		t.Errorf("expected API keys %v, got %v", want, apiKeys)
+	}
+}
+
+func TestCredentialProcessCachesValidKeys(t *testing.T) {
+	process := newTestCredentialProcess(t, time.Hour)
+
+	for range 3 {
+		apiKey, err := process.APIKey(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if apiKey != "key-1" {
+			t.Errorf("expected the cached key-1, got %q", apiKey)
+		}
+	}
+}
+
+func TestCredentialProcessFailure(t *testing.T) {
+	process := newTestCredentialProcess(t, time.Hour)
+	t.Setenv("CLOUDBACK_TEST_CREDENTIAL_FAIL", "1")
+
+	_, err := process.APIKey(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "vault is sealed") {
+		t.Errorf("expected the error output of the process, got: %v", err)
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	tests := map[string][]string{
+		`cloudback-vault get`:                    {"cloudback-vault", "get"},
+		`  "/opt/my tools/vault"  --field  key `: {"/opt/my tools/vault", "--field", "key"},
+		`vault read -field='api key' secret/cb`:  {"vault", "read", "-field=api key", "secret/cb"},
+		`echo ""`:                                {"echo", ""},
+	}
+
+	for command, want := range tests {
+		got, err := splitCommand(command)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", command, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %q, got %q", command, want, got)
+		}
+	}
+
+	if _, err := splitCommand(`vault "read`); err == nil {
+		t.Error("expected an error for an unterminated quote, got none")
+	}
+}
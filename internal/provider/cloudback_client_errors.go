@@ -0,0 +1,208 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Sentinel errors classifying API failures. An *APIError matches them with
+// errors.Is according to its status code, e.g.
+//
+//	if errors.Is(err, ErrNotFound) { ... }
+var (
+	ErrNotFound     = errors.New("cloudback: not found")
+	ErrUnauthorized = errors.New("cloudback: unauthorized")
+	ErrForbidden    = errors.New("cloudback: forbidden")
+	ErrConflict     = errors.New("cloudback: conflict")
+)
+
+// ErrReadOnly is returned without contacting the API when a client created
+// with WithReadOnly is asked to send a mutating request.
+var ErrReadOnly = errors.New("cloudback: read-only mode, refusing to send a mutating request")
+
+// APIError is returned by CloudbackClient when the API answers with a non
+// successful status code. The fields besides StatusCode and Status are
+// filled from the response body when the API provides them.
+type APIError struct {
+	StatusCode int
+	Status     string
+
+	// Code is the machine readable error code, e.g. "ValidationFailed".
+	Code string
+	// Message is the human readable description of the failure.
+	Message string
+	// RequestID identifies the request in the Cloudback logs and should be
+	// quoted when contacting support.
+	RequestID string
+	// FieldErrors lists validation failures of individual request fields.
+	FieldErrors []FieldError
+}
+
+// FieldError describes a validation failure of a single request field.
+// Field uses the snake_case dotted form of the Terraform schema, e.g.
+// "settings.storage".
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// apiErrorBody is the error document returned by the Cloudback API. Both the
+// native format and the RFC 7807 problem details format are accepted.
+type apiErrorBody struct {
+	Code      string          `json:"code"`
+	Message   string          `json:"message"`
+	RequestID string          `json:"requestId"`
+	Title     string          `json:"title"`
+	Detail    string          `json:"detail"`
+	TraceID   string          `json:"traceId"`
+	Errors    json.RawMessage `json:"errors"`
+}
+
+type apiFieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func NewAPIError(resp *resty.Response) error {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode(),
+		Status:     resp.Status(),
+		RequestID:  resp.Header().Get(requestIDHeader),
+	}
+
+	var body apiErrorBody
+	if err := json.Unmarshal(resp.Body(), &body); err != nil {
+		// Not every failure carries a JSON document, e.g. errors produced
+		// by a gateway in front of the API.
+		return apiErr
+	}
+
+	apiErr.Code = body.Code
+	apiErr.Message = firstNonEmpty(body.Message, body.Detail, body.Title)
+	apiErr.RequestID = firstNonEmpty(body.RequestID, body.TraceID, apiErr.RequestID)
+	apiErr.FieldErrors = decodeFieldErrors(body.Errors)
+
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	var sb strings.Builder
+
+	sb.WriteString(e.Status)
+
+	if e.Code != "" {
+		fmt.Fprintf(&sb, " [%s]", e.Code)
+	}
+
+	if e.Message != "" {
+		fmt.Fprintf(&sb, ": %s", e.Message)
+	}
+
+	for _, fieldErr := range e.FieldErrors {
+		fmt.Fprintf(&sb, "; %s: %s", fieldErr.Field, fieldErr.Message)
+	}
+
+	if e.RequestID != "" {
+		fmt.Fprintf(&sb, " (request ID: %s)", e.RequestID)
+	}
+
+	return sb.String()
+}
+
+// Is lets errors.Is match an *APIError against the sentinel errors above.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusGone
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	default:
+		return false
+	}
+}
+
+// decodeFieldErrors accepts either a list of {field, message} objects or a
+// map of field names to lists of messages.
+func decodeFieldErrors(raw json.RawMessage) []FieldError {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var list []apiFieldError
+	if err := json.Unmarshal(raw, &list); err == nil {
+		fieldErrors := make([]FieldError, 0, len(list))
+		for _, item := range list {
+			fieldErrors = append(fieldErrors, FieldError{
+				Field:   normalizeFieldName(item.Field),
+				Message: item.Message,
+			})
+		}
+		return fieldErrors
+	}
+
+	var byField map[string][]string
+	if err := json.Unmarshal(raw, &byField); err == nil {
+		fields := make([]string, 0, len(byField))
+		for field := range byField {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		var fieldErrors []FieldError
+		for _, field := range fields {
+			for _, message := range byField[field] {
+				fieldErrors = append(fieldErrors, FieldError{
+					Field:   normalizeFieldName(field),
+					Message: message,
+				})
+			}
+		}
+		return fieldErrors
+	}
+
+	return nil
+}
+
+// normalizeFieldName converts an API field name such as "Settings.Storage"
+// or "subjectName" into the snake_case form used by the Terraform schema.
+func normalizeFieldName(field string) string {
+	field = strings.TrimPrefix(field, "$.")
+	segments := strings.Split(field, ".")
+
+	for i, segment := range segments {
+		var sb strings.Builder
+		for j, r := range segment {
+			if unicode.IsUpper(r) {
+				if j > 0 {
+					sb.WriteRune('_')
+				}
+				r = unicode.ToLower(r)
+			}
+			sb.WriteRune(r)
+		}
+		segments[i] = sb.String()
+	}
+
+	return strings.Join(segments, ".")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
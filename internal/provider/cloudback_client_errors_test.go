@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewAPIErrorDecodesBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{
+  "code": "ValidationFailed",
+  "message": "The backup definition is invalid",
+  "requestId": "req-123",
+  "errors": [{"field": "settings.storage", "message": "Storage 'Nope' does not exist"}]
+}`))
+	}))
+	defer server.Close()
+
+	err := newTestClient(server).UpdateBackupDefinition(context.Background(), "GitHub", "testland", "Repository", "docs", BackupDefinitionSettings{})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %T", err)
+	}
+
+	if apiErr.Code != "ValidationFailed" || apiErr.Message != "The backup definition is invalid" || apiErr.RequestID != "req-123" {
+		t.Errorf("unexpected error fields: %+v", apiErr)
+	}
+
+	if len(apiErr.FieldErrors) != 1 || apiErr.FieldErrors[0].Field != "settings.storage" {
+		t.Errorf("unexpected field errors: %+v", apiErr.FieldErrors)
+	}
+
+	expected := "400 Bad Request [ValidationFailed]: The backup definition is invalid; settings.storage: Storage 'Nope' does not exist (request ID: req-123)"
+	if err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestNewAPIErrorDecodesProblemDetails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{
+  "title": "One or more validation errors occurred.",
+  "traceId": "00-abc-01",
+  "errors": {"Settings.Retention": ["Unknown retention policy"], "SubjectName": ["Required"]}
+}`))
+	}))
+	defer server.Close()
+
+	err := newTestClient(server).UpdateBackupDefinition(context.Background(), "GitHub", "testland", "Repository", "docs", BackupDefinitionSettings{})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %T", err)
+	}
+
+	if apiErr.Message != "One or more validation errors occurred." || apiErr.RequestID != "00-abc-01" {
+		t.Errorf("unexpected error fields: %+v", apiErr)
+	}
+
+	expected := []FieldError{
+		{Field: "settings.retention", Message: "Unknown retention policy"},
+		{Field: "subject_name", Message: "Required"},
+	}
+	if len(apiErr.FieldErrors) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, apiErr.FieldErrors)
+	}
+	for i := range expected {
+		if apiErr.FieldErrors[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], apiErr.FieldErrors[i])
+		}
+	}
+}
+
+func TestNewAPIErrorWithoutBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", "gw-1")
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(server).GetBackupDefinition(context.Background(), "GitHub", "testland", "Repository", "docs")
+
+	expected := "401 Unauthorized (request ID: gw-1)"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %q, got %v", expected, err)
+	}
+}
+
+func TestAPIErrorMatchesSentinelErrors(t *testing.T) {
+	testCases := map[int]error{
+		http.StatusNotFound:     ErrNotFound,
+		http.StatusGone:         ErrNotFound,
+		http.StatusUnauthorized: ErrUnauthorized,
+		http.StatusForbidden:    ErrForbidden,
+		http.StatusConflict:     ErrConflict,
+	}
+
+	sentinels := []error{ErrNotFound, ErrUnauthorized, ErrForbidden, ErrConflict}
+
+	for statusCode, expected := range testCases {
+		var err error = &APIError{StatusCode: statusCode, Status: http.StatusText(statusCode)}
+
+		for _, sentinel := range sentinels {
+			if got := errors.Is(err, sentinel); got != (sentinel == expected) {
+				t.Errorf("errors.Is(%d, %v): expected %t, got %t", statusCode, sentinel, sentinel == expected, got)
+			}
+		}
+	}
+}
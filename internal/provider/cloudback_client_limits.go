@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultRequestsPerSecond     = 10
+	defaultMaxConcurrentRequests = 10
+)
+
+// rateLimit returns a resty middleware that holds every attempt, including
+// retries, until the client-side rate limit lets it through. It runs before
+// the attempt is sent, so the wait counts against the deadline of the
+// operation but not against the request timeout.
+func rateLimit(requestsPerSecond float64) resty.RequestMiddleware {
+	burst := int(math.Ceil(requestsPerSecond))
+	limiter := rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
+
+	return func(_ *resty.Client, req *resty.Request) error {
+		return waitForToken(req.Context(), limiter)
+	}
+}
+
+// waitForToken waits until limiter grants a token. Unlike
+// rate.Limiter.Wait it does not fail early when the wait would outlast the
+// deadline of ctx, so an error always means that ctx is done.
+func waitForToken(ctx context.Context, limiter *rate.Limiter) error {
+	reservation := limiter.Reserve()
+
+	delay := reservation.Delay()
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		reservation.Cancel()
+		return ctx.Err()
+	}
+}
+
+// limitedTransport applies the concurrency cap to every attempt, including
+// retries, sent through the shared client.
+type limitedTransport struct {
+	next  http.RoundTripper
+	slots chan struct{}
+}
+
+func newLimitedTransport(next http.RoundTripper, maxConcurrentRequests int) http.RoundTripper {
+	if maxConcurrentRequests <= 0 {
+		return next
+	}
+
+	return &limitedTransport{
+		next:  next,
+		slots: make(chan struct{}, maxConcurrentRequests),
+	}
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	select {
+	case t.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() { <-t.slots })
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	// The slot is held until the body has been consumed, since the
+	// connection stays busy until then.
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+
+	return resp, nil
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	defer b.release()
+
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCloudbackClientCapsConcurrentRequests(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		for {
+			observed := atomic.LoadInt32(&maxInFlight)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxInFlight, observed, current) {
+				break
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := NewCloudbackClient(server.URL, "test-key", WithRateLimit(0), WithMaxConcurrentRequests(2))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := client.UpdateBackupDefinition(context.Background(), "GitHub", "testland", "Repository", "docs", BackupDefinitionSettings{}); err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&maxInFlight); got > 2 {
+		t.Errorf("expected at most 2 concurrent requests, got %d", got)
+	}
+}
+
+func TestCloudbackClientRateLimitHonorsContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := NewCloudbackClient(server.URL, "test-key", WithRateLimit(0.001))
+
+	// The first request consumes the only token in the bucket.
+	if err := client.UpdateBackupDefinition(context.Background(), "GitHub", "testland", "Repository", "docs", BackupDefinitionSettings{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := client.UpdateBackupDefinition(ctx, "GitHub", "testland", "Repository", "docs", BackupDefinitionSettings{}); err == nil {
+		t.Fatal("expected the rate limited request to fail, got nil")
+	}
+}
+
+func TestCloudbackClientRateLimitWaitIsNotARequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// The third request waits about 500ms for a token, well past the
+	// request timeout. Retries are disabled, so the wait must not eat into
+	// the only attempt.
+	client := NewCloudbackClient(server.URL, "test-key",
+		WithRateLimit(2),
+		WithRequestTimeout(100*time.Millisecond),
+		WithRetryConfig(RetryConfig{MaxAttempts: 1}),
+	)
+
+	for i := 0; i < 3; i++ {
+		if err := client.UpdateBackupDefinition(context.Background(), "GitHub", "testland", "Repository", "docs", BackupDefinitionSettings{}); err != nil {
+			t.Fatalf("request %d: unexpected error: %s", i+1, err)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// httpLogSubsystem is the tflog subsystem receiving one entry per API
+	// attempt. Its level can be tuned separately through the
+	// TF_LOG_PROVIDER_CLOUDBACK_HTTP environment variable.
+	httpLogSubsystem = "cloudback_http"
+
+	// requestIDHeader carries the identifier Cloudback support uses to look
+	// up a request.
+	requestIDHeader = "X-Request-ID"
+
+	redactedValue = "***"
+)
+
+// sensitiveHeaders are never written to the logs verbatim.
+var sensitiveHeaders = []string{
+	"X-API-KEY",
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// withHTTPLogging prepares ctx for logging through the cloudback_http
+// subsystem, masking the API key wherever it would appear.
+func (c *CloudbackClient) withHTTPLogging(ctx context.Context) context.Context {
+	ctx = tflog.NewSubsystem(ctx, httpLogSubsystem,
+		tflog.WithLevelFromEnv("TF_LOG_PROVIDER_CLOUDBACK_HTTP"),
+		tflog.WithRootFields(),
+	)
+
+	apiKeys := []string{c.ApiKey}
+	if c.credentialProcess != nil {
+		apiKeys = append(apiKeys, c.credentialProcess.current())
+	}
+
+	for _, apiKey := range apiKeys {
+		if apiKey != "" {
+			ctx = tflog.SubsystemMaskAllFieldValuesStrings(ctx, httpLogSubsystem, apiKey)
+			ctx = tflog.SubsystemMaskMessageStrings(ctx, httpLogSubsystem, apiKey)
+		}
+	}
+
+	return ctx
+}
+
+// restyLogger forwards the messages resty logs about failed attempts to the
+// cloudback_http subsystem instead of stderr, so that they are redacted and
+// follow the Terraform log level.
+type restyLogger struct {
+	ctx context.Context
+}
+
+func (l restyLogger) Errorf(format string, v ...interface{}) {
+	tflog.SubsystemError(l.ctx, httpLogSubsystem, fmt.Sprintf(format, v...))
+}
+
+func (l restyLogger) Warnf(format string, v ...interface{}) {
+	tflog.SubsystemWarn(l.ctx, httpLogSubsystem, fmt.Sprintf(format, v...))
+}
+
+func (l restyLogger) Debugf(format string, v ...interface{}) {
+	tflog.SubsystemDebug(l.ctx, httpLogSubsystem, fmt.Sprintf(format, v...))
+}
+
+// loggingTransport logs method, path, status, latency and request ID of every
+// attempt at debug level, and the redacted headers and bodies at trace level.
+type loggingTransport struct {
+	next http.RoundTripper
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	fields := map[string]interface{}{
+		"http_method": req.Method,
+		"http_path":   req.URL.Path,
+	}
+
+	tflog.SubsystemTrace(ctx, httpLogSubsystem, "sending API request", fields, map[string]interface{}{
+		"http_request_headers": redactHeaders(req.Header),
+		"http_request_body":    requestBody(req),
+	})
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	if err != nil {
+		fields["http_duration_ms"] = time.Since(start).Milliseconds()
+		fields["error"] = err.Error()
+		tflog.SubsystemDebug(ctx, httpLogSubsystem, "API request failed", fields)
+		return nil, err
+	}
+
+	// Buffer the body so it can be logged and still be decoded by resty.
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	fields["http_duration_ms"] = time.Since(start).Milliseconds()
+	fields["http_status"] = resp.StatusCode
+	fields["request_id"] = resp.Header.Get(requestIDHeader)
+
+	if err != nil {
+		fields["error"] = err.Error()
+		tflog.SubsystemDebug(ctx, httpLogSubsystem, "API response could not be read", fields)
+		return nil, err
+	}
+
+	tflog.SubsystemDebug(ctx, httpLogSubsystem, "received API response", fields)
+	tflog.SubsystemTrace(ctx, httpLogSubsystem, "API response details", fields, map[string]interface{}{
+		"http_response_headers": redactHeaders(resp.Header),
+		"http_response_body":    string(body),
+	})
+
+	return resp, nil
+}
+
+// requestBody returns a copy of the request body without consuming it.
+func requestBody(req *http.Request) string {
+	if req.GetBody == nil {
+		return ""
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return ""
+	}
+
+	return string(data)
+}
+
+func redactHeaders(header http.Header) map[string]string {
+	redacted := make(map[string]string, len(header))
+
+	for name, values := range header {
+		redacted[name] = strings.Join(values, ", ")
+	}
+
+	for _, name := range sensitiveHeaders {
+		canonical := http.CanonicalHeaderKey(name)
+		if _, ok := redacted[canonical]; ok {
+			redacted[canonical] = redactedValue
+		}
+	}
+
+	return redacted
+}
@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestCloudbackClientLogsRedactedRequests(t *testing.T) {
+	t.Setenv("TF_LOG_PROVIDER_CLOUDBACK_HTTP", "TRACE")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestIDHeader, "req-42")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"settings":{"enabled":true}}`))
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	client := NewCloudbackClient(server.URL, "super-secret-key")
+	if _, err := client.GetBackupDefinition(ctx, "GitHub", "testland", "Repository", "docs"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if strings.Contains(output.String(), "super-secret-key") {
+		t.Errorf("API key leaked into the logs:\n%s", output.String())
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatalf("unable to decode log output: %s", err)
+	}
+
+	var response map[string]interface{}
+	for _, entry := range entries {
+		if entry["@message"] == "received API response" {
+			response = entry
+		}
+	}
+
+	if response == nil {
+		t.Fatalf("no response entry logged, got: %v", entries)
+	}
+
+	expected := map[string]interface{}{
+		"@module":     "provider." + httpLogSubsystem,
+		"http_method": http.MethodPost,
+		"http_path":   "/ops/definition/get",
+		"http_status": float64(http.StatusOK),
+		"request_id":  "req-42",
+	}
+
+	for key, value := range expected {
+		if response[key] != value {
+			t.Errorf("expected %s to be %v, got %v", key, value, response[key])
+		}
+	}
+
+	if _, ok := response["http_duration_ms"]; !ok {
+		t.Error("expected http_duration_ms to be logged")
+	}
+}
+
+func TestCloudbackClientLogsRetriesThroughSubsystem(t *testing.T) {
+	t.Setenv("TF_LOG_PROVIDER_CLOUDBACK_HTTP", "TRACE")
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			// Drop the connection without answering.
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			_ = conn.Close()
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"settings":{"enabled":true}}`))
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	client := NewCloudbackClient(server.URL, "super-secret-key", WithRetryConfig(RetryConfig{
+		MaxAttempts: 2,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  time.Millisecond,
+	}))
+	if _, err := client.GetBackupDefinition(ctx, "GitHub", "testland", "Repository", "docs"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatalf("unable to decode log output: %s", err)
+	}
+
+	for _, entry := range entries {
+		message, _ := entry["@message"].(string)
+		if entry["@level"] == "warn" && entry["@module"] == "provider."+httpLogSubsystem && strings.Contains(message, "Attempt 1") {
+			return
+		}
+	}
+
+	t.Errorf("expected the failed attempt to be logged through %s, got: %v", httpLogSubsystem, entries)
+}
@@ -0,0 +1,137 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/url"
+	"time"
+)
+
+// defaultRequestTimeout bounds a single attempt, so that a hung connection
+// is retried instead of blocking the operation until its deadline.
+const defaultRequestTimeout = time.Minute
+
+// ClientOption customizes a CloudbackClient created by NewCloudbackClient.
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	requestsPerSecond     float64
+	maxConcurrentRequests int
+
+	proxyURL           *url.URL
+	rootCAs            *x509.CertPool
+	certificates       []tls.Certificate
+	insecureSkipVerify bool
+	headers            map[string]string
+
+	userAgent string
+
+	credentialProcess *CredentialProcess
+
+	retry          RetryConfig
+	requestTimeout time.Duration
+
+	readOnly bool
+}
+
+func defaultClientOptions() clientOptions {
+	return clientOptions{
+		requestsPerSecond:     defaultRequestsPerSecond,
+		maxConcurrentRequests: defaultMaxConcurrentRequests,
+		retry:                 DefaultRetryConfig(),
+		requestTimeout:        defaultRequestTimeout,
+	}
+}
+
+// WithRateLimit limits the client to requestsPerSecond requests on average.
+// Zero disables the limit.
+func WithRateLimit(requestsPerSecond float64) ClientOption {
+	return func(o *clientOptions) {
+		o.requestsPerSecond = requestsPerSecond
+	}
+}
+
+// WithMaxConcurrentRequests limits the number of requests in flight at any
+// time. Zero disables the limit.
+func WithMaxConcurrentRequests(maxConcurrentRequests int) ClientOption {
+	return func(o *clientOptions) {
+		o.maxConcurrentRequests = maxConcurrentRequests
+	}
+}
+
+// WithProxyURL sends all requests through the given proxy instead of the one
+// configured through the HTTPS_PROXY environment variable.
+func WithProxyURL(proxyURL *url.URL) ClientOption {
+	return func(o *clientOptions) {
+		o.proxyURL = proxyURL
+	}
+}
+
+// WithRootCAs replaces the certificate authorities used to verify the API
+// server certificate.
+func WithRootCAs(rootCAs *x509.CertPool) ClientOption {
+	return func(o *clientOptions) {
+		o.rootCAs = rootCAs
+	}
+}
+
+// WithClientCertificate presents cert to servers requesting mutual TLS.
+func WithClientCertificate(cert tls.Certificate) ClientOption {
+	return func(o *clientOptions) {
+		o.certificates = append(o.certificates, cert)
+	}
+}
+
+// WithInsecureSkipVerify disables verification of the server certificate.
+// It is meant for testing only.
+func WithInsecureSkipVerify(insecureSkipVerify bool) ClientOption {
+	return func(o *clientOptions) {
+		o.insecureSkipVerify = insecureSkipVerify
+	}
+}
+
+// WithHeaders adds extra headers, e.g. required by an API gateway, to every
+// request. They cannot override the headers set by the client itself.
+func WithHeaders(headers map[string]string) ClientOption {
+	return func(o *clientOptions) {
+		o.headers = headers
+	}
+}
+
+// WithUserAgent sets the User-Agent header identifying the caller.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(o *clientOptions) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithRetryConfig replaces the default retry settings.
+func WithRetryConfig(retry RetryConfig) ClientOption {
+	return func(o *clientOptions) {
+		o.retry = retry
+	}
+}
+
+// WithRequestTimeout limits the time a single attempt may take, including
+// reading the response body. Zero disables the limit.
+func WithRequestTimeout(requestTimeout time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.requestTimeout = requestTimeout
+	}
+}
+
+// WithCredentialProcess sends the API key obtained from process instead of
+// the static one, renewing it whenever it expires.
+func WithCredentialProcess(process *CredentialProcess) ClientOption {
+	return func(o *clientOptions) {
+		o.credentialProcess = process
+	}
+}
+
+// WithReadOnly makes the client refuse every request that would change data
+// in Cloudback, failing with ErrReadOnly.
+func WithReadOnly() ClientOption {
+	return func(o *clientOptions) {
+		o.readOnly = true
+	}
+}
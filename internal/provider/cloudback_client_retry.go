@@ -0,0 +1,162 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	// defaultRetryMaxAttempts is the total number of attempts, including the
+	// first one, made for an idempotent request.
+	defaultRetryMaxAttempts = 5
+	defaultRetryMinBackoff  = 1 * time.Second
+	defaultRetryMaxBackoff  = 30 * time.Second
+)
+
+// defaultRetryableStatusCodes are the response codes the Cloudback API uses
+// for throttling and transient gateway failures.
+var defaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryConfig controls how idempotent requests are retried.
+type RetryConfig struct {
+	// MaxAttempts is the total number of attempts, including the first
+	// one. One disables retries.
+	MaxAttempts int
+	// MinBackoff is the wait before the first retry. Later waits grow
+	// exponentially, with jitter, up to MaxBackoff.
+	MinBackoff time.Duration
+	// MaxBackoff caps the wait between attempts, including waits requested
+	// by the API through Retry-After.
+	MaxBackoff time.Duration
+	// RetryableStatusCodes are the response codes that trigger a retry.
+	// Transport errors such as connection resets are always retried.
+	RetryableStatusCodes []int
+}
+
+// DefaultRetryConfig returns the retry settings used unless configured
+// otherwise.
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:          defaultRetryMaxAttempts,
+		MinBackoff:           defaultRetryMinBackoff,
+		MaxBackoff:           defaultRetryMaxBackoff,
+		RetryableStatusCodes: append([]int(nil), defaultRetryableStatusCodes...),
+	}
+}
+
+// configureRetries sets up capped exponential backoff with jitter on the
+// underlying resty client. Whether a given request is retried at all is
+// decided per request by shouldRetry.
+func (c *CloudbackClient) configureRetries(config RetryConfig) {
+	c.retryableStatusCodes = make(map[int]bool, len(config.RetryableStatusCodes))
+	for _, code := range config.RetryableStatusCodes {
+		c.retryableStatusCodes[code] = true
+	}
+
+	c.restyClient.
+		SetRetryCount(max(config.MaxAttempts-1, 0)).
+		SetRetryWaitTime(config.MinBackoff).
+		SetRetryMaxWaitTime(config.MaxBackoff).
+		SetRetryAfter(retryAfter)
+}
+
+// shouldRetry reports whether a failed attempt is worth repeating, either
+// because the API answered with a retryable status or because the
+// connection failed before a response was received. Nothing is retried once
+// the operation context ctx is done, while an attempt that only ran into
+// the request timeout is.
+func (c *CloudbackClient) shouldRetry(ctx context.Context, resp *resty.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return isRetryableError(err)
+	}
+
+	return resp != nil && c.retryableStatusCodes[resp.StatusCode()]
+}
+
+// isRetryableError reports whether a transport error is transient.
+// Cancellation is never retried. The request timeout surfaces as
+// context.DeadlineExceeded too, so shouldRetry checks the operation context
+// before calling it.
+func isRetryableError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	if errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	// An unknown host is a configuration error, asking again won't help.
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
+
+// retryAfter honors the Retry-After header sent with throttled or
+// unavailable responses. Returning zero lets resty fall back to its jittered
+// exponential backoff. Resty caps the result at the maximum backoff.
+func retryAfter(_ *resty.Client, resp *resty.Response) (time.Duration, error) {
+	if resp == nil {
+		return 0, nil
+	}
+
+	return parseRetryAfter(resp.Header().Get("Retry-After"), time.Now()), nil
+}
+
+// parseRetryAfter accepts both forms allowed by RFC 9110: a number of
+// seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,221 @@
+package provider
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a client pointed at server with backoff shortened so
+// retry tests finish quickly.
+func newTestClient(server *httptest.Server) *CloudbackClient {
+	retry := DefaultRetryConfig()
+	retry.MinBackoff = time.Millisecond
+	retry.MaxBackoff = 10 * time.Millisecond
+
+	return NewCloudbackClient(server.URL, "test-key", WithRetryConfig(retry))
+}
+
+func TestCloudbackClientRetriesThrottledRequests(t *testing.T) {
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(BackupDefinition{
+			Settings: BackupDefinitionSettings{Enabled: true, Schedule: "Daily at 9 pm"},
+		})
+	}))
+	defer server.Close()
+
+	definition, err := newTestClient(server).GetBackupDefinition(context.Background(), "GitHub", "testland", "Repository", "docs")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("expected 3 attempts, got %d", got)
+	}
+
+	if definition.Settings.Schedule != "Daily at 9 pm" {
+		t.Errorf("unexpected schedule %q", definition.Settings.Schedule)
+	}
+}
+
+func TestCloudbackClientGivesUpAfterMaxAttempts(t *testing.T) {
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	err := newTestClient(server).UpdateBackupDefinition(context.Background(), "GitHub", "testland", "Repository", "docs", BackupDefinitionSettings{})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if got := atomic.LoadInt32(&attempts); got != defaultRetryMaxAttempts {
+		t.Errorf("expected %d attempts, got %d", defaultRetryMaxAttempts, got)
+	}
+}
+
+func TestCloudbackClientDoesNotRetryClientErrors(t *testing.T) {
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(server).GetBackupDefinition(context.Background(), "GitHub", "testland", "Repository", "docs")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Errorf("expected 1 attempt, got %d", got)
+	}
+}
+
+func TestCloudbackClientStopsRetryingWhenContextIsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(server).GetBackupDefinition(ctx, "GitHub", "testland", "Repository", "docs")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Errorf("expected 1 attempt, got %d", got)
+	}
+}
+
+func TestCloudbackClientRetriesRequestTimeouts(t *testing.T) {
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			// Hang past the request timeout. The server notices the
+			// client giving up only once the body has been read.
+			_, _ = io.Copy(io.Discard, r.Body)
+			<-r.Context().Done()
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(BackupDefinition{})
+	}))
+	defer server.Close()
+
+	retry := DefaultRetryConfig()
+	retry.MinBackoff = time.Millisecond
+	retry.MaxBackoff = 10 * time.Millisecond
+
+	client := NewCloudbackClient(server.URL, "test-key", WithRetryConfig(retry), WithRequestTimeout(50*time.Millisecond))
+	if _, err := client.GetBackupDefinition(context.Background(), "GitHub", "testland", "Repository", "docs"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Errorf("expected 2 attempts, got %d", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := map[string]time.Duration{
+		"":                              0,
+		"7":                             7 * time.Second,
+		"-1":                            0,
+		"Thu, 01 Jan 2026 12:00:30 GMT": 30 * time.Second,
+		"Thu, 01 Jan 2026 11:00:00 GMT": 0,
+		"soon":                          0,
+	}
+
+	for value, expected := range testCases {
+		if got := parseRetryAfter(value, now); got != expected {
+			t.Errorf("parseRetryAfter(%q): expected %s, got %s", value, expected, got)
+		}
+	}
+}
+
+func TestCloudbackClientTransportOptions(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Gateway-Token"); got != "gateway" {
+			t.Errorf("expected gateway header, got %q", got)
+		}
+
+		if got := r.Header.Get("X-API-KEY"); got != "test-key" {
+			t.Errorf("expected API key header not to be overridden, got %q", got)
+		}
+	}))
+	defer server.Close()
+
+	settings := BackupDefinitionSettings{}
+
+	untrusted := NewCloudbackClient(server.URL, "test-key", WithHeaders(map[string]string{"X-Gateway-Token": "gateway"}))
+	if err := untrusted.UpdateBackupDefinition(context.Background(), "GitHub", "testland", "Repository", "docs", settings); err == nil {
+		t.Fatal("expected certificate verification to fail, got nil")
+	}
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+
+	trusted := NewCloudbackClient(server.URL, "test-key",
+		WithRootCAs(rootCAs),
+		WithHeaders(map[string]string{"X-Gateway-Token": "gateway", "X-API-KEY": "override"}),
+	)
+	if err := trusted.UpdateBackupDefinition(context.Background(), "GitHub", "testland", "Repository", "docs", settings); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestCloudbackClientReadOnly(t *testing.T) {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(BackupDefinition{})
+	}))
+	defer server.Close()
+
+	client := NewCloudbackClient(server.URL, "test-key", WithReadOnly())
+
+	if _, err := client.GetBackupDefinition(context.Background(), "GitHub", "testland", "Repository", "docs"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err := client.UpdateBackupDefinition(context.Background(), "GitHub", "testland", "Repository", "docs", BackupDefinitionSettings{})
+	if !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly, got: %v", err)
+	}
+
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("expected only the read request to be sent, got %d requests", got)
+	}
+}
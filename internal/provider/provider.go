@@ -2,13 +2,23 @@ package provider
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure CloudbackProvider satisfies various provider interfaces.
@@ -24,8 +34,48 @@ type CloudbackProvider struct {
 
 // CloudbackProviderModel describes the provider data model.
 type CloudbackProviderModel struct {
-	ApiKey   types.String `tfsdk:"api_key"`
-	Endpoint types.String `tfsdk:"endpoint"`
+	ApiKey                    types.String                   `tfsdk:"api_key"`
+	Endpoint                  types.String                   `tfsdk:"endpoint"`
+	MaxRequestsPerSecond      types.Float64                  `tfsdk:"max_requests_per_second"`
+	MaxConcurrentRequests     types.Int64                    `tfsdk:"max_concurrent_requests"`
+	ProxyURL                  types.String                   `tfsdk:"proxy_url"`
+	CACertPEM                 types.String                   `tfsdk:"ca_cert_pem"`
+	CACertFile                types.String                   `tfsdk:"ca_cert_file"`
+	ClientCert                types.String                   `tfsdk:"client_cert"`
+	ClientKey                 types.String                   `tfsdk:"client_key"`
+	InsecureSkipVerify        types.Bool                     `tfsdk:"insecure_skip_verify"`
+	HTTPHeaders               types.Map                      `tfsdk:"http_headers"`
+	RequestTimeout            types.String                   `tfsdk:"request_timeout"`
+	Profile                   types.String                   `tfsdk:"profile"`
+	SharedCredentialsFile     types.String                   `tfsdk:"shared_credentials_file"`
+	CredentialProcess         types.String                   `tfsdk:"credential_process"`
+	SkipCredentialsValidation types.Bool                     `tfsdk:"skip_credentials_validation"`
+	AllowInsecureHTTP         types.Bool                     `tfsdk:"allow_insecure_http"`
+	ReadOnly                  types.Bool                     `tfsdk:"read_only"`
+	Retry                     *CloudbackRetryModel           `tfsdk:"retry"`
+	DefaultPlatform           types.String                   `tfsdk:"default_platform"`
+	DefaultAccount            types.String                   `tfsdk:"default_account"`
+	DefaultSettings           *BackupDefinitionSettingsModel `tfsdk:"default_settings"`
+}
+
+// CloudbackProviderData is handed to resources and data sources by
+// Configure.
+type CloudbackProviderData struct {
+	// Client is nil while the provider configuration is not known yet.
+	Client *CloudbackClient
+
+	// DefaultPlatform and DefaultAccount are used by backup definitions
+	// that leave platform or account unset. They are null without default
+	// and unknown while the provider configuration is not known yet.
+	DefaultPlatform types.String
+	DefaultAccount  types.String
+
+	// DefaultSettings fills in the settings a backup definition leaves
+	// unset. Null fields have no default.
+	DefaultSettings BackupDefinitionSettingsModel
+
+	// ReadOnly rejects plans that would change anything in Cloudback.
+	ReadOnly bool
 }
 
 func (p *CloudbackProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -38,24 +88,101 @@ func (p *CloudbackProvider) Schema(ctx context.Context, req provider.SchemaReque
 		Description: "The Cloudback provider allows you to manage backup definitions.",
 		Attributes: map[string]schema.Attribute{
 			"api_key": schema.StringAttribute{
-				MarkdownDescription: "The API key for authentication. May also be provided via CLOUDBACK_API_KEY environment variable.",
+				MarkdownDescription: "The API key for authentication. May also be provided via CLOUDBACK_API_KEY environment variable or the shared credentials file.",
 				Optional:            true,
 				Sensitive:           true,
 			},
 			"endpoint": schema.StringAttribute{
-				MarkdownDescription: "The API endpoint URL. May also be provided via CLOUDBACK_ENDPOINT environment variable. Default is https://app.cloudback.it.",
+				MarkdownDescription: "The API endpoint URL. Must use https:// unless allow_insecure_http is set. May also be provided via CLOUDBACK_ENDPOINT environment variable or the shared credentials file. Default is https://app.cloudback.it.",
 				Required:            false,
 				Optional:            true,
+				Validators: []validator.String{
+					endpointValidator{},
+				},
+			},
+			"allow_insecure_http": schema.BoolAttribute{
+				MarkdownDescription: "Allow an http:// endpoint, which sends the API key unencrypted. Use for testing only, e.g. against a local fake API. May also be provided via CLOUDBACK_ALLOW_INSECURE_HTTP environment variable. Default is false.",
+				Optional:            true,
+			},
+			"max_requests_per_second": schema.Float64Attribute{
+				MarkdownDescription: "The maximum average number of API requests per second sent by this provider instance, shared by all resources. May also be provided via CLOUDBACK_MAX_REQUESTS_PER_SECOND environment variable. Default is 10, 0 disables the limit.",
+				Optional:            true,
+			},
+			"max_concurrent_requests": schema.Int64Attribute{
+				MarkdownDescription: "The maximum number of API requests in flight at any time for this provider instance, shared by all resources. May also be provided via CLOUDBACK_MAX_CONCURRENT_REQUESTS environment variable. Default is 10, 0 disables the limit.",
+				Optional:            true,
+			},
+			"proxy_url": schema.StringAttribute{
+				MarkdownDescription: "The URL of the HTTP proxy used to reach the API. Defaults to the proxy configured through the HTTPS_PROXY environment variable.",
+				Optional:            true,
+			},
+			"ca_cert_pem": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded certificate authorities trusted in addition to the system ones, e.g. the one of a TLS inspecting proxy.",
+				Optional:            true,
+			},
+			"ca_cert_file": schema.StringAttribute{
+				MarkdownDescription: "Path to a file with PEM encoded certificate authorities trusted in addition to the system ones.",
+				Optional:            true,
+			},
+			"client_cert": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded client certificate presented when the server requests mutual TLS. Requires client_key.",
+				Optional:            true,
+			},
+			"client_key": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded private key of client_cert.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"insecure_skip_verify": schema.BoolAttribute{
+				MarkdownDescription: "Skip verification of the API server certificate. Use for testing only.",
+				Optional:            true,
+			},
+			"http_headers": schema.MapAttribute{
+				MarkdownDescription: "Extra headers sent with every API request, e.g. required by an API gateway.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"request_timeout": schema.StringAttribute{
+				MarkdownDescription: "The maximum time a single API request may take, e.g. `30s`. Requests that time out are retried. May also be provided via CLOUDBACK_REQUEST_TIMEOUT environment variable. Default is 1m.",
+				Optional:            true,
+			},
+			"profile": schema.StringAttribute{
+				MarkdownDescription: "The profile of the shared credentials file to read the api_key and endpoint from. May also be provided via CLOUDBACK_PROFILE environment variable. Default is `default`.",
+				Optional:            true,
+			},
+			"shared_credentials_file": schema.StringAttribute{
+				MarkdownDescription: "The path of the shared credentials file. May also be provided via CLOUDBACK_SHARED_CREDENTIALS_FILE environment variable. Default is `~/.cloudback/credentials`.",
+				Optional:            true,
+			},
+			"credential_process": schema.StringAttribute{
+				MarkdownDescription: "A command that prints the API key as a JSON document `{\"Version\": 1, \"ApiKey\": \"...\", \"Expiration\": \"<RFC 3339 time>\"}` to stdout, e.g. to read it from a secrets manager. Used when no api_key is set. The command runs again when the key expires. May also be provided via CLOUDBACK_CREDENTIAL_PROCESS environment variable or the shared credentials file.",
+				Optional:            true,
 			},
+			"skip_credentials_validation": schema.BoolAttribute{
+				MarkdownDescription: "Skip checking the API key and endpoint with an authenticated API call while configuring the provider. May also be provided via CLOUDBACK_SKIP_CREDENTIALS_VALIDATION environment variable. Default is false.",
+				Optional:            true,
+			},
+			"default_platform": schema.StringAttribute{
+				MarkdownDescription: "The platform of backup definitions that do not set one, e.g. `GitHub`. May also be provided via CLOUDBACK_DEFAULT_PLATFORM environment variable or the shared credentials file.",
+				Optional:            true,
+			},
+			"default_account": schema.StringAttribute{
+				MarkdownDescription: "The account of backup definitions that do not set one. May also be provided via CLOUDBACK_DEFAULT_ACCOUNT environment variable or the shared credentials file.",
+				Optional:            true,
+			},
+			"read_only": schema.BoolAttribute{
+				MarkdownDescription: "Reject every plan that would create, update or delete a resource, and refuse to send mutating API requests, e.g. for drift detection with a read-scoped API key. May also be provided via CLOUDBACK_READ_ONLY environment variable. Default is false.",
+				Optional:            true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"retry":            retryBlockSchema(),
+			"default_settings": defaultSettingsBlockSchema(),
 		},
 	}
 }
 
 func (p *CloudbackProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
-	// Check environment variables
-	apiKey := os.Getenv("CLOUDBACK_API_KEY")
-	endpoint := os.Getenv("CLOUDBACK_ENDPOINT")
-
 	var data CloudbackProviderModel
 
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
@@ -64,33 +191,185 @@ func (p *CloudbackProvider) Configure(ctx context.Context, req provider.Configur
 		return
 	}
 
-	// Check configuration data, which should take precedence over
-	// environment variable data, if found.
-	if data.ApiKey.ValueString() != "" {
-		apiKey = data.ApiKey.ValueString()
+	// Values derived from other resources are unknown until they are
+	// applied. Terraform clients supporting deferred actions postpone the
+	// resources of this provider until then, older ones get resources that
+	// plan without calling the API.
+	unknown := unknownConnectionAttributes(req.Config.Raw)
+	if len(unknown) > 0 && req.ClientCapabilities.DeferralAllowed {
+		resp.Deferred = &provider.Deferred{Reason: provider.DeferredReasonProviderConfigUnknown}
+		return
+	}
+
+	// Configuration data takes precedence over environment variables,
+	// which take precedence over the selected profile of the shared
+	// credentials file.
+	var profile credentialsProfile
+	profileKnown := !data.Profile.IsUnknown() && !data.SharedCredentialsFile.IsUnknown()
+	if profileKnown {
+		profile = loadProfile(data, &resp.Diagnostics)
 	}
 
-	if data.Endpoint.ValueString() != "" {
-		endpoint = data.Endpoint.ValueString()
+	providerData := &CloudbackProviderData{
+		DefaultPlatform: defaultSetting(data.DefaultPlatform, "CLOUDBACK_DEFAULT_PLATFORM", profile, "default_platform", profileKnown),
+		DefaultAccount:  defaultSetting(data.DefaultAccount, "CLOUDBACK_DEFAULT_ACCOUNT", profile, "default_account", profileKnown),
 	}
+	if data.DefaultSettings != nil {
+		providerData.DefaultSettings = *data.DefaultSettings
+	}
+
+	readOnly := data.ReadOnly
+	if readOnly.IsNull() {
+		readOnly = envBool(&resp.Diagnostics, "CLOUDBACK_READ_ONLY")
+	}
+	providerData.ReadOnly = readOnly.ValueBool()
+
+	if len(unknown) > 0 {
+		tflog.Warn(ctx, "Provider configuration is not known yet, skipping Cloudback API calls until apply", map[string]interface{}{
+			"unknown_attributes": unknown,
+		})
 
-	if apiKey == "" {
+		resp.DataSourceData = providerData
+		resp.ResourceData = providerData
+		return
+	}
+
+	apiKey := stringSetting(data.ApiKey, "CLOUDBACK_API_KEY", profile, "api_key")
+	endpoint := stringSetting(data.Endpoint, "CLOUDBACK_ENDPOINT", profile, "endpoint")
+
+	var opts []ClientOption
+
+	// An API key configured anywhere takes precedence over the credential
+	// process, which is run right away so that failures surface here.
+	command := stringSetting(data.CredentialProcess, "CLOUDBACK_CREDENTIAL_PROCESS", profile, "credential_process")
+	if apiKey == "" && command != "" {
+		process, err := NewCredentialProcess(command)
+		if err == nil {
+			apiKey, err = process.APIKey(ctx)
+		}
+
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("credential_process"),
+				"Unable to Run Credential Process",
+				fmt.Sprintf("While configuring the provider, the API key could not be obtained from the credential process, got error: %s", err),
+			)
+		} else {
+			opts = append(opts, WithCredentialProcess(process))
+		}
+	}
+
+	if apiKey == "" && command == "" {
 		resp.Diagnostics.AddError(
 			"Missing API Key Configuration",
 			"While configuring the provider, the API key was not found in "+
-				"the CLOUDBACK_API_KEY environment variable or provider "+
-				"configuration block api_key attribute.",
+				"the CLOUDBACK_API_KEY environment variable, provider "+
+				"configuration block api_key attribute or shared credentials "+
+				"file profile, and no credential_process was set.",
 		)
 		// Not returning early allows the logic to collect all errors.
 	}
 
 	if endpoint == "" {
-		endpoint = "https://app.cloudback.it"
+		endpoint = defaultEndpoint
+	}
+
+	allowInsecureHTTP := data.AllowInsecureHTTP
+	if allowInsecureHTTP.IsNull() {
+		allowInsecureHTTP = envBool(&resp.Diagnostics, "CLOUDBACK_ALLOW_INSECURE_HTTP")
+	}
+
+	normalizedEndpoint, err := normalizeEndpoint(endpoint, allowInsecureHTTP.ValueBool())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("endpoint"),
+			"Invalid Endpoint",
+			fmt.Sprintf("While configuring the provider, the endpoint %q was invalid: %s.", endpoint, err),
+		)
+	}
+	endpoint = normalizedEndpoint
+
+	maxRequestsPerSecond := data.MaxRequestsPerSecond
+	if maxRequestsPerSecond.IsNull() {
+		maxRequestsPerSecond = envFloat64(&resp.Diagnostics, "CLOUDBACK_MAX_REQUESTS_PER_SECOND")
+	}
+
+	if !maxRequestsPerSecond.IsNull() {
+		if maxRequestsPerSecond.ValueFloat64() < 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("max_requests_per_second"),
+				"Invalid Rate Limit",
+				"The maximum number of requests per second must not be negative.",
+			)
+		}
+		opts = append(opts, WithRateLimit(maxRequestsPerSecond.ValueFloat64()))
+	}
+
+	maxConcurrentRequests := data.MaxConcurrentRequests
+	if maxConcurrentRequests.IsNull() {
+		maxConcurrentRequests = envInt64(&resp.Diagnostics, "CLOUDBACK_MAX_CONCURRENT_REQUESTS")
+	}
+
+	if !maxConcurrentRequests.IsNull() {
+		if maxConcurrentRequests.ValueInt64() < 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("max_concurrent_requests"),
+				"Invalid Concurrency Limit",
+				"The maximum number of concurrent requests must not be negative.",
+			)
+		}
+		opts = append(opts, WithMaxConcurrentRequests(int(maxConcurrentRequests.ValueInt64())))
+	}
+
+	requestTimeout := data.RequestTimeout.ValueString()
+	if data.RequestTimeout.IsNull() {
+		requestTimeout = os.Getenv("CLOUDBACK_REQUEST_TIMEOUT")
+	}
+
+	if requestTimeout != "" {
+		timeout, err := time.ParseDuration(requestTimeout)
+		if err != nil || timeout < 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("request_timeout"),
+				"Invalid Request Timeout",
+				fmt.Sprintf("The request timeout must be a non-negative duration such as 30s or 2m, got: %q", requestTimeout),
+			)
+		}
+		opts = append(opts, WithRequestTimeout(timeout))
+	}
+
+	opts = append(opts, WithRetryConfig(retryConfig(ctx, data.Retry, &resp.Diagnostics)))
+	opts = append(opts, transportClientOptions(ctx, data, &resp.Diagnostics)...)
+	opts = append(opts, WithUserAgent(userAgent(p.version, req.TerraformVersion)))
+	if providerData.ReadOnly {
+		opts = append(opts, WithReadOnly())
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	// Create data/clients and persist to resp.DataSourceData, resp.ResourceData,
-	client := NewCloudbackClient(endpoint, apiKey)
-	resp.ResourceData = client
+	// Create data/clients and persist to resp.DataSourceData, resp.ResourceData.
+	// A single client is shared so that all resources and data sources of
+	// this provider instance draw from the same request budget.
+	client := NewCloudbackClient(endpoint, apiKey, opts...)
+
+	skipCredentialsValidation := data.SkipCredentialsValidation
+	if skipCredentialsValidation.IsNull() {
+		skipCredentialsValidation = envBool(&resp.Diagnostics, "CLOUDBACK_SKIP_CREDENTIALS_VALIDATION")
+	}
+
+	if !skipCredentialsValidation.ValueBool() {
+		validateCredentials(ctx, client, &resp.Diagnostics)
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	providerData.Client = client
+	resp.DataSourceData = providerData
+	resp.ResourceData = providerData
 }
 
 func (p *CloudbackProvider) Resources(ctx context.Context) []func() resource.Resource {
@@ -103,6 +382,111 @@ func (p *CloudbackProvider) DataSources(ctx context.Context) []func() datasource
 	return nil
 }
 
+// userAgent identifies the provider and Terraform versions to the API.
+// Users may append their own product tokens through TF_APPEND_USER_AGENT.
+func userAgent(providerVersion, terraformVersion string) string {
+	ua := fmt.Sprintf("terraform-provider-cloudback/%s", providerVersion)
+
+	if terraformVersion != "" {
+		ua += fmt.Sprintf(" terraform/%s", terraformVersion)
+	}
+
+	if suffix := strings.TrimSpace(os.Getenv("TF_APPEND_USER_AGENT")); suffix != "" {
+		ua += " " + suffix
+	}
+
+	return ua
+}
+
+// unknownConnectionAttributes returns the names of the top level provider
+// attributes that are not known yet, apart from the defaults for resources,
+// which do not affect the connection to the API.
+func unknownConnectionAttributes(config tftypes.Value) []string {
+	unknown := map[string]bool{}
+
+	_ = tftypes.Walk(config, func(attributePath *tftypes.AttributePath, value tftypes.Value) (bool, error) {
+		if value.IsKnown() {
+			return true, nil
+		}
+
+		if steps := attributePath.Steps(); len(steps) > 0 {
+			if name, ok := steps[0].(tftypes.AttributeName); ok && !strings.HasPrefix(string(name), "default_") {
+				unknown[string(name)] = true
+			}
+		}
+
+		return false, nil
+	})
+
+	names := make([]string, 0, len(unknown))
+	for name := range unknown {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// envInt64 reads an integer setting from the environment. It returns a null
+// value when the variable is unset.
+func envInt64(diags *diag.Diagnostics, name string) types.Int64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return types.Int64Null()
+	}
+
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		diags.AddError(
+			"Invalid Environment Variable",
+			fmt.Sprintf("The %s environment variable must be an integer, got: %q", name, value),
+		)
+		return types.Int64Null()
+	}
+
+	return types.Int64Value(parsed)
+}
+
+// envBool reads a boolean setting from the environment. It returns a null
+// value when the variable is unset.
+func envBool(diags *diag.Diagnostics, name string) types.Bool {
+	value := os.Getenv(name)
+	if value == "" {
+		return types.BoolNull()
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		diags.AddError(
+			"Invalid Environment Variable",
+			fmt.Sprintf("The %s environment variable must be true or false, got: %q", name, value),
+		)
+		return types.BoolNull()
+	}
+
+	return types.BoolValue(parsed)
+}
+
+// envFloat64 reads a numeric setting from the environment. It returns a null
+// value when the variable is unset.
+func envFloat64(diags *diag.Diagnostics, name string) types.Float64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return types.Float64Null()
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		diags.AddError(
+			"Invalid Environment Variable",
+			fmt.Sprintf("The %s environment variable must be a number, got: %q", name, value),
+		)
+		return types.Float64Null()
+	}
+
+	return types.Float64Value(parsed)
+}
+
 func New(version string) func() provider.Provider {
 	return func() provider.Provider {
 		return &CloudbackProvider{
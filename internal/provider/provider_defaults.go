@@ -0,0 +1,29 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+)
+
+func defaultSettingsBlockSchema() schema.Block {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Settings used by every `cloudback_backup_definition` that leaves them unset in its `settings` attribute.",
+		Attributes: map[string]schema.Attribute{
+			"enabled": schema.BoolAttribute{
+				MarkdownDescription: "Whether the backup is scheduled",
+				Optional:            true,
+			},
+			"schedule": schema.StringAttribute{
+				MarkdownDescription: "Backup schedule name",
+				Optional:            true,
+			},
+			"storage": schema.StringAttribute{
+				MarkdownDescription: "Storage name",
+				Optional:            true,
+			},
+			"retention": schema.StringAttribute{
+				MarkdownDescription: "Retention policy name",
+				Optional:            true,
+			},
+		},
+	}
+}
@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const defaultEndpoint = "https://app.cloudback.it"
+
+// normalizeEndpoint checks that endpoint is an absolute HTTPS URL, or HTTP
+// when allowInsecureHTTP is set, and strips trailing slashes so that request
+// paths can be appended.
+func normalizeEndpoint(endpoint string, allowInsecureHTTP bool) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("the endpoint must be an absolute URL such as %s: %w", defaultEndpoint, err)
+	}
+
+	switch {
+	case u.Scheme == "" || u.Host == "":
+		return "", fmt.Errorf("the endpoint must be an absolute URL such as %s, including the https:// scheme", defaultEndpoint)
+	case u.Scheme == "http" && !allowInsecureHTTP:
+		return "", errors.New("the endpoint must use https://, plain http:// sends the API key unencrypted and requires allow_insecure_http = true")
+	case u.Scheme != "https" && u.Scheme != "http":
+		return "", fmt.Errorf("the endpoint must use https://, got scheme %q", u.Scheme)
+	case u.User != nil || u.RawQuery != "" || u.Fragment != "":
+		return "", errors.New("the endpoint must not contain credentials, a query or a fragment")
+	}
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+
+	return u.String(), nil
+}
+
+// endpointValidator reports an invalid endpoint attribute at plan time. The
+// endpoint from the environment or a profile is checked by Configure.
+// Plain http:// is accepted when allow_insecure_http or, if it is null,
+// CLOUDBACK_ALLOW_INSECURE_HTTP is true.
+type endpointValidator struct{}
+
+var _ validator.String = endpointValidator{}
+
+func (v endpointValidator) Description(ctx context.Context) string {
+	return "value must be an absolute https:// URL, or http:// when allow_insecure_http is true"
+}
+
+func (v endpointValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v endpointValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	var allowInsecureHTTP types.Bool
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("allow_insecure_http"), &allowInsecureHTTP)...)
+
+	// Like Configure, fall back to the environment. An invalid value is
+	// reported by Configure.
+	if allowInsecureHTTP.IsNull() {
+		allowInsecureHTTP = envBool(&diag.Diagnostics{}, "CLOUDBACK_ALLOW_INSECURE_HTTP")
+	}
+
+	// An unknown allow_insecure_http might still permit http://, so only
+	// the other checks apply until it is known.
+	allow := allowInsecureHTTP.ValueBool() || allowInsecureHTTP.IsUnknown()
+
+	if _, err := normalizeEndpoint(req.ConfigValue.ValueString(), allow); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid Endpoint", fmt.Sprintf("Invalid endpoint %q: %s.", req.ConfigValue.ValueString(), err))
+	}
+}
@@ -0,0 +1,160 @@
+package provider
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const defaultProfileName = "default"
+
+// credentialsProfile holds the settings of one profile of the shared
+// credentials file, keyed by provider attribute name, e.g. api_key.
+type credentialsProfile map[string]string
+
+// defaultSharedCredentialsFile returns ~/.cloudback/credentials.
+func defaultSharedCredentialsFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".cloudback", "credentials")
+}
+
+// loadProfile returns the selected profile of the shared credentials file.
+// The profile is chosen by the profile attribute, then CLOUDBACK_PROFILE,
+// then "default". A missing file or default profile is not an error unless
+// a profile was explicitly selected.
+func loadProfile(data CloudbackProviderModel, diags *diag.Diagnostics) credentialsProfile {
+	name, explicit := data.Profile.ValueString(), true
+	if data.Profile.IsNull() {
+		name = os.Getenv("CLOUDBACK_PROFILE")
+	}
+	if name == "" {
+		name, explicit = defaultProfileName, false
+	}
+
+	filename := data.SharedCredentialsFile.ValueString()
+	if data.SharedCredentialsFile.IsNull() {
+		filename = os.Getenv("CLOUDBACK_SHARED_CREDENTIALS_FILE")
+	}
+	if filename == "" {
+		filename = defaultSharedCredentialsFile()
+	}
+	if filename == "" {
+		return nil
+	}
+
+	profiles, err := readCredentialsFile(filename)
+	if errors.Is(err, fs.ErrNotExist) && !explicit && data.SharedCredentialsFile.IsNull() {
+		return nil
+	}
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("shared_credentials_file"),
+			"Unable to Read Shared Credentials File",
+			fmt.Sprintf("Unable to read %s, got error: %s", filename, err),
+		)
+		return nil
+	}
+
+	profile, ok := profiles[name]
+	if !ok && explicit {
+		diags.AddAttributeError(
+			path.Root("profile"),
+			"Unknown Profile",
+			fmt.Sprintf("The profile %q was not found in %s.", name, filename),
+		)
+	}
+
+	return profile
+}
+
+// readCredentialsFile parses an INI style credentials file:
+//
+//	[default]
+//	api_key  = ...
+//
+//	[staging]
+//	api_key  = ...
+//	endpoint = https://staging.example.com
+func readCredentialsFile(filename string) (map[string]credentialsProfile, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	profiles := map[string]credentialsProfile{}
+	var current credentialsProfile
+
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if _, ok := profiles[name]; !ok {
+				profiles[name] = credentialsProfile{}
+			}
+			current = profiles[name]
+		default:
+			key, value, ok := strings.Cut(line, "=")
+			if !ok || current == nil {
+				return nil, fmt.Errorf("line %d: expected a [profile] header or a key = value pair", lineNumber)
+			}
+			current[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+
+	return profiles, scanner.Err()
+}
+
+// stringSetting resolves a string setting with the documented precedence:
+// provider configuration, then environment variable, then profile.
+func stringSetting(value types.String, envVar string, profile credentialsProfile, key string) string {
+	if value.ValueString() != "" {
+		return value.ValueString()
+	}
+
+	if fromEnv := os.Getenv(envVar); fromEnv != "" {
+		return fromEnv
+	}
+
+	return profile[key]
+}
+
+// defaultSetting resolves a provider default for resources with the same
+// precedence as stringSetting. It is null when not set anywhere and unknown
+// when it might come from a profile that is not known yet.
+func defaultSetting(value types.String, envVar string, profile credentialsProfile, key string, profileKnown bool) types.String {
+	if value.IsUnknown() || value.ValueString() != "" {
+		return value
+	}
+
+	if fromEnv := os.Getenv(envVar); fromEnv != "" {
+		return types.StringValue(fromEnv)
+	}
+
+	if !profileKnown {
+		return types.StringUnknown()
+	}
+
+	if fromProfile := profile[key]; fromProfile != "" {
+		return types.StringValue(fromProfile)
+	}
+
+	return types.StringNull()
+}
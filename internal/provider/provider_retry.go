@@ -0,0 +1,152 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// CloudbackRetryModel describes the retry block of the provider.
+type CloudbackRetryModel struct {
+	MaxAttempts          types.Int64  `tfsdk:"max_attempts"`
+	MinBackoff           types.String `tfsdk:"min_backoff"`
+	MaxBackoff           types.String `tfsdk:"max_backoff"`
+	RetryableStatusCodes types.List   `tfsdk:"retryable_status_codes"`
+}
+
+func retryBlockSchema() schema.Block {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Controls how throttled and failed API requests are retried. Only requests that are safe to replay are retried.",
+		Attributes: map[string]schema.Attribute{
+			"max_attempts": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("The total number of attempts per request, including the first one. 1 disables retries. May also be provided via CLOUDBACK_RETRY_MAX_ATTEMPTS environment variable. Default is %d.", defaultRetryMaxAttempts),
+				Optional:            true,
+			},
+			"min_backoff": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("The wait before the first retry, e.g. `500ms`. Later waits grow exponentially with jitter. May also be provided via CLOUDBACK_RETRY_MIN_BACKOFF environment variable. Default is %s.", defaultRetryMinBackoff),
+				Optional:            true,
+			},
+			"max_backoff": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("The maximum wait between attempts, also applied to waits requested by the API through Retry-After. May also be provided via CLOUDBACK_RETRY_MAX_BACKOFF environment variable. Default is %s.", defaultRetryMaxBackoff),
+				Optional:            true,
+			},
+			"retryable_status_codes": schema.ListAttribute{
+				MarkdownDescription: "The HTTP status codes that trigger a retry. Connection failures are always retried. May also be provided as a comma separated list via CLOUDBACK_RETRY_STATUS_CODES environment variable. Default is [429, 502, 503, 504].",
+				ElementType:         types.Int64Type,
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// retryConfig merges the retry block and the CLOUDBACK_RETRY_* environment
+// variables into the default retry settings. Configuration takes precedence
+// over the environment.
+func retryConfig(ctx context.Context, data *CloudbackRetryModel, diags *diag.Diagnostics) RetryConfig {
+	config := DefaultRetryConfig()
+
+	if data == nil {
+		data = &CloudbackRetryModel{}
+	}
+
+	maxAttemptsPath := path.Root("retry").AtName("max_attempts")
+	maxAttempts := data.MaxAttempts
+	if maxAttempts.IsNull() {
+		maxAttempts = envInt64(diags, "CLOUDBACK_RETRY_MAX_ATTEMPTS")
+	}
+	if !maxAttempts.IsNull() {
+		if maxAttempts.ValueInt64() < 1 {
+			diags.AddAttributeError(maxAttemptsPath, "Invalid Retry Configuration", "The maximum number of attempts must be at least 1.")
+		}
+		config.MaxAttempts = int(maxAttempts.ValueInt64())
+	}
+
+	if backoff, ok := retryDuration(diags, path.Root("retry").AtName("min_backoff"), data.MinBackoff, "CLOUDBACK_RETRY_MIN_BACKOFF"); ok {
+		config.MinBackoff = backoff
+	}
+
+	if backoff, ok := retryDuration(diags, path.Root("retry").AtName("max_backoff"), data.MaxBackoff, "CLOUDBACK_RETRY_MAX_BACKOFF"); ok {
+		config.MaxBackoff = backoff
+	}
+
+	if config.MinBackoff > config.MaxBackoff {
+		diags.AddAttributeError(
+			path.Root("retry").AtName("min_backoff"),
+			"Invalid Retry Configuration",
+			fmt.Sprintf("The minimum backoff %s must not exceed the maximum backoff %s.", config.MinBackoff, config.MaxBackoff),
+		)
+	}
+
+	statusCodesPath := path.Root("retry").AtName("retryable_status_codes")
+	if !data.RetryableStatusCodes.IsNull() {
+		var statusCodes []int64
+		diags.Append(data.RetryableStatusCodes.ElementsAs(ctx, &statusCodes, false)...)
+
+		config.RetryableStatusCodes = nil
+		for _, statusCode := range statusCodes {
+			config.RetryableStatusCodes = append(config.RetryableStatusCodes, int(statusCode))
+		}
+	} else if value := os.Getenv("CLOUDBACK_RETRY_STATUS_CODES"); value != "" {
+		config.RetryableStatusCodes = nil
+		for _, field := range strings.Split(value, ",") {
+			statusCode, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil {
+				diags.AddError(
+					"Invalid Environment Variable",
+					fmt.Sprintf("The CLOUDBACK_RETRY_STATUS_CODES environment variable must be a comma separated list of status codes, got: %q", value),
+				)
+				break
+			}
+			config.RetryableStatusCodes = append(config.RetryableStatusCodes, statusCode)
+		}
+	}
+
+	for _, statusCode := range config.RetryableStatusCodes {
+		if statusCode < 400 || statusCode > 599 {
+			diags.AddAttributeError(
+				statusCodesPath,
+				"Invalid Retry Configuration",
+				fmt.Sprintf("Only error status codes between 400 and 599 can be retried, got: %d", statusCode),
+			)
+		}
+	}
+
+	return config
+}
+
+// retryDuration parses a duration from the configuration, or from the
+// environment variable when it is not configured. It reports false when
+// neither is set or the value is invalid.
+func retryDuration(diags *diag.Diagnostics, attributePath path.Path, value types.String, envVar string) (time.Duration, bool) {
+	raw := value.ValueString()
+	source := "the value"
+
+	if value.IsNull() {
+		raw = os.Getenv(envVar)
+		source = fmt.Sprintf("the %s environment variable", envVar)
+	}
+
+	if raw == "" {
+		return 0, false
+	}
+
+	duration, err := time.ParseDuration(raw)
+	if err != nil || duration < 0 {
+		diags.AddAttributeError(
+			attributePath,
+			"Invalid Retry Configuration",
+			fmt.Sprintf("Expected %s to be a non-negative duration such as 500ms or 30s, got: %q", source, raw),
+		)
+		return 0, false
+	}
+
+	return duration, true
+}
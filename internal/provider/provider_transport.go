@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// transportClientOptions translates the proxy, TLS and header settings of
+// the provider configuration into client options.
+func transportClientOptions(ctx context.Context, data CloudbackProviderModel, diags *diag.Diagnostics) []ClientOption {
+	var opts []ClientOption
+
+	if proxyURL := data.ProxyURL.ValueString(); proxyURL != "" {
+		parsed, err := url.Parse(proxyURL)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			diags.AddAttributeError(
+				path.Root("proxy_url"),
+				"Invalid Proxy URL",
+				fmt.Sprintf("The proxy URL must be an absolute URL such as http://proxy.example.com:3128, got: %q", proxyURL),
+			)
+		} else {
+			opts = append(opts, WithProxyURL(parsed))
+		}
+	}
+
+	if rootCAs := rootCAsFromConfig(data, diags); rootCAs != nil {
+		opts = append(opts, WithRootCAs(rootCAs))
+	}
+
+	clientCert, clientKey := data.ClientCert.ValueString(), data.ClientKey.ValueString()
+
+	switch {
+	case clientCert != "" && clientKey != "":
+		cert, err := tls.X509KeyPair([]byte(clientCert), []byte(clientKey))
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("client_cert"),
+				"Invalid Client Certificate",
+				fmt.Sprintf("Unable to load the client certificate and key, got error: %s", err),
+			)
+		} else {
+			opts = append(opts, WithClientCertificate(cert))
+		}
+	case clientCert != "":
+		diags.AddAttributeError(
+			path.Root("client_key"),
+			"Missing Client Key",
+			"The client_key attribute must be set together with client_cert.",
+		)
+	case clientKey != "":
+		diags.AddAttributeError(
+			path.Root("client_cert"),
+			"Missing Client Certificate",
+			"The client_cert attribute must be set together with client_key.",
+		)
+	}
+
+	if data.InsecureSkipVerify.ValueBool() {
+		opts = append(opts, WithInsecureSkipVerify(true))
+	}
+
+	if !data.HTTPHeaders.IsNull() {
+		headers := make(map[string]string, len(data.HTTPHeaders.Elements()))
+		diags.Append(data.HTTPHeaders.ElementsAs(ctx, &headers, false)...)
+		opts = append(opts, WithHeaders(headers))
+	}
+
+	return opts
+}
+
+// rootCAsFromConfig returns the system certificate pool extended with the
+// configured certificate authorities, or nil when none are configured.
+func rootCAsFromConfig(data CloudbackProviderModel, diags *diag.Diagnostics) *x509.CertPool {
+	caCertPEM, caCertFile := data.CACertPEM.ValueString(), data.CACertFile.ValueString()
+
+	if caCertPEM == "" && caCertFile == "" {
+		return nil
+	}
+
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil {
+		rootCAs = x509.NewCertPool()
+	}
+
+	if caCertPEM != "" && !rootCAs.AppendCertsFromPEM([]byte(caCertPEM)) {
+		diags.AddAttributeError(
+			path.Root("ca_cert_pem"),
+			"Invalid CA Certificate",
+			"The ca_cert_pem attribute does not contain any PEM encoded certificate.",
+		)
+	}
+
+	if caCertFile != "" {
+		pem, err := os.ReadFile(caCertFile)
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("ca_cert_file"),
+				"Unable to Read CA Certificate",
+				fmt.Sprintf("Unable to read %s, got error: %s", caCertFile, err),
+			)
+		} else if !rootCAs.AppendCertsFromPEM(pem) {
+			diags.AddAttributeError(
+				path.Root("ca_cert_file"),
+				"Invalid CA Certificate",
+				fmt.Sprintf("The file %s does not contain any PEM encoded certificate.", caCertFile),
+			)
+		}
+	}
+
+	return rootCAs
+}
@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// skipValidationHint is appended to every credential validation error.
+const skipValidationHint = "Set skip_credentials_validation = true to skip this check."
+
+// credentialsValidationTimeout bounds the credential check, which makes a
+// single attempt instead of following the retry policy.
+const credentialsValidationTimeout = 10 * time.Second
+
+// validateCredentials checks the API key and endpoint with one cheap
+// authenticated call, so that a misconfiguration is reported once instead of
+// by every resource. The API has no endpoint meant for this, so it reads a
+// definition without a subject through the documented definition endpoint.
+// Apart from a rejected key, only a client error carrying a Cloudback error
+// document shows that the key was accepted; any other answer comes from
+// something that is not the Cloudback API.
+func validateCredentials(ctx context.Context, client *CloudbackClient, diags *diag.Diagnostics) {
+	ctx, cancel := context.WithTimeout(ctx, credentialsValidationTimeout)
+	defer cancel()
+
+	err := client.probe(ctx)
+
+	var (
+		apiErr    *APIError
+		certErr   *tls.CertificateVerificationError
+		recordErr tls.RecordHeaderError
+		netErr    net.Error
+		opErr     *net.OpError
+	)
+
+	switch {
+	case errors.Is(err, ErrUnauthorized), errors.Is(err, ErrForbidden):
+		diags.AddAttributeError(
+			path.Root("api_key"),
+			"Invalid API Key",
+			fmt.Sprintf("The Cloudback API at %s rejected the API key, got error: %s\n\n%s", client.Endpoint, err, skipValidationHint),
+		)
+	case err == nil, errors.As(err, &apiErr) && isCloudbackClientError(apiErr):
+		tflog.Debug(ctx, "Validated Cloudback credentials", map[string]interface{}{"endpoint": client.Endpoint})
+	case errors.As(err, &apiErr):
+		diags.AddAttributeError(
+			path.Root("endpoint"),
+			"Unexpected Endpoint",
+			fmt.Sprintf("%s does not look like the Cloudback API, got error: %s\n\n%s", client.Endpoint, err, skipValidationHint),
+		)
+	case errors.As(err, &certErr):
+		diags.AddAttributeError(
+			path.Root("endpoint"),
+			"Untrusted Server Certificate",
+			fmt.Sprintf("The certificate of %s could not be verified, got error: %s\n\n"+
+				"If the API is reached through a TLS inspecting proxy, trust its certificate authority with ca_cert_pem or ca_cert_file. %s",
+				client.Endpoint, err, skipValidationHint),
+		)
+	// net/http reports an HTTP answer to a TLS handshake only through the
+	// message of an unwrapped error.
+	case errors.As(err, &recordErr), strings.Contains(err.Error(), "server gave HTTP response to HTTPS client"):
+		diags.AddAttributeError(
+			path.Root("endpoint"),
+			"TLS Handshake Failed",
+			fmt.Sprintf("%s did not answer with TLS, check the scheme and port of the endpoint, got error: %s\n\n%s", client.Endpoint, err, skipValidationHint),
+		)
+	case errors.As(err, &opErr), errors.As(err, &netErr) && netErr.Timeout():
+		diags.AddAttributeError(
+			path.Root("endpoint"),
+			"Unable to Reach Endpoint",
+			fmt.Sprintf("The Cloudback API at %s could not be reached, got error: %s\n\n%s", client.Endpoint, err, skipValidationHint),
+		)
+	default:
+		diags.AddError(
+			"Unable to Validate Credentials",
+			fmt.Sprintf("The credentials could not be checked against %s, got error: %s\n\n%s", client.Endpoint, err, skipValidationHint),
+		)
+	}
+}
+
+// isCloudbackClientError reports whether apiErr is a client error described
+// by a Cloudback error document, rather than e.g. the not found page of an
+// unrelated server or a failing gateway.
+func isCloudbackClientError(apiErr *APIError) bool {
+	return apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 &&
+		(apiErr.Code != "" || apiErr.Message != "")
+}